@@ -0,0 +1,193 @@
+// Package assets implements access to game StreamingAssets that can be
+// stored as directory, zip or tar archive.
+package assets
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+)
+
+// Dir is name of assets directory in game installation.
+const Dir = "StreamingAssets"
+
+// maxDepth limits search of assets directory in nested layouts like
+// steamapps/common/Stationeers/rocketstation_Data/StreamingAssets.
+const maxDepth = 6
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
+
+// Open opens assets from directory, .zip, .tar, .tar.gz or .tgz archive.
+//
+// If there is a StreamingAssets directory inside, like in Steam
+// installation or zipped assets, the returned filesystem is rooted there.
+// The returned closer should be closed after use.
+func Open(name string) (fs.FS, io.Closer, error) {
+	stat, err := os.Stat(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	var (
+		fsys   fs.FS
+		closer io.Closer = nopCloser{}
+	)
+	lower := strings.ToLower(name)
+	switch {
+	case stat.IsDir():
+		fsys = os.DirFS(name)
+	case strings.HasSuffix(lower, ".zip"):
+		z, err := zip.OpenReader(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		fsys, closer = z, z
+	case strings.HasSuffix(lower, ".tar"),
+		strings.HasSuffix(lower, ".tar.gz"),
+		strings.HasSuffix(lower, ".tgz"):
+		f, err := os.Open(name)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer f.Close()
+		var r io.Reader = f
+		if !strings.HasSuffix(lower, ".tar") {
+			gz, err := gzip.NewReader(f)
+			if err != nil {
+				return nil, nil, err
+			}
+			defer gz.Close()
+			r = gz
+		}
+		if fsys, err = ReadTar(r); err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %v", name, err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported assets format: %s", name)
+	}
+	root, err := Find(fsys)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	if root == "." {
+		return fsys, closer, nil
+	}
+	sub, err := fs.Sub(fsys, root)
+	if err != nil {
+		closer.Close()
+		return nil, nil, err
+	}
+	return sub, closer, nil
+}
+
+// ReadTar reads uncompressed tar archive to in-memory filesystem.
+func ReadTar(r io.Reader) (fs.FS, error) {
+	// Re-packing to zip, because zip.Reader already implements fs.FS.
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	t := tar.NewReader(r)
+	for {
+		h, err := t.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if h.Typeflag != tar.TypeReg {
+			continue
+		}
+		f, err := w.CreateHeader(&zip.FileHeader{
+			Name:     strings.TrimPrefix(path.Clean(h.Name), "/"),
+			Method:   zip.Store,
+			Modified: h.ModTime,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err = io.Copy(f, t); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+}
+
+// Find returns path of StreamingAssets directory in fsys, or "." if
+// there is no such directory.
+//
+// Directory that contains "Language" directory is also treated as
+// StreamingAssets, so archives of renamed directory are supported, unless
+// there is StreamingAssets directory. Language directories of scenarios
+// inside such directory are ignored. Returns error if there are several
+// candidates.
+func Find(fsys fs.FS) (string, error) {
+	if stat, err := fs.Stat(fsys, "Language"); err == nil && stat.IsDir() {
+		// Fast path for assets directory itself.
+		return ".", nil
+	}
+	var assets, roots []string
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		switch d.Name() {
+		case Dir:
+			assets = append(assets, name)
+			return fs.SkipDir
+		case "Language":
+			roots = append(roots, path.Dir(name))
+			return fs.SkipDir
+		}
+		if name != "." && strings.Count(name, "/") >= maxDepth {
+			return fs.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	candidates := assets
+	if len(candidates) == 0 {
+		candidates = outermost(roots)
+	}
+	switch len(candidates) {
+	case 0:
+		return ".", nil
+	case 1:
+		return candidates[0], nil
+	default:
+		return "", fmt.Errorf("several assets directories found: %s", strings.Join(candidates, ", "))
+	}
+}
+
+// outermost returns directories that are not inside other ones.
+func outermost(dirs []string) []string {
+	var result []string
+	for _, dir := range dirs {
+		nested := false
+		for _, other := range dirs {
+			if other != dir && strings.HasPrefix(dir, other+"/") {
+				nested = true
+			}
+		}
+		if !nested {
+			result = append(result, dir)
+		}
+	}
+	return result
+}
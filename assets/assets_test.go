@@ -0,0 +1,167 @@
+package assets
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+var testFiles = map[string]string{
+	"steamapps/common/Stationeers/rocketstation_Data/StreamingAssets/Language/english.xml":                    "<Language/>",
+	"steamapps/common/Stationeers/rocketstation_Data/StreamingAssets/Scenario/Mars/Language/english_mars.xml": "<Language/>",
+	"steamapps/common/Stationeers/rocketstation.exe":                                                          "",
+}
+
+func writeZip(t *testing.T, w io.Writer) {
+	t.Helper()
+	z := zip.NewWriter(w)
+	for name, content := range testFiles {
+		f, err := z.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = io.WriteString(f, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := z.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTar(t *testing.T, w io.Writer) {
+	t.Helper()
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for name, content := range testFiles {
+		if err := tw.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(content)),
+			Typeflag: tar.TypeReg,
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(tw, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range testFiles {
+		p := filepath.Join(dir, "steam", filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, tt := range []struct {
+		name  string
+		write func(t *testing.T, w io.Writer)
+	}{
+		{"assets.zip", writeZip},
+		{"assets.tar.gz", writeTar},
+	} {
+		f, err := os.Create(filepath.Join(dir, tt.name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		tt.write(t, f)
+		if err = f.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range []string{
+		"steam",
+		"assets.zip",
+		"assets.tar.gz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			fsys, closer, err := Open(filepath.Join(dir, name))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer closer.Close()
+			for _, p := range []string{
+				"Language/english.xml",
+				"Scenario/Mars/Language/english_mars.xml",
+			} {
+				data, err := fs.ReadFile(fsys, p)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(data) != "<Language/>" {
+					t.Errorf("unexpected content of %s", p)
+				}
+			}
+		})
+	}
+}
+
+func TestFind(t *testing.T) {
+	for _, tt := range []struct {
+		Name  string
+		Files []string
+		Root  string
+		Error bool
+	}{
+		{Name: "Assets", Root: ".", Files: []string{"Language/english.xml"}},
+		{Name: "Empty", Root: ".", Files: []string{"readme.txt"}},
+		{Name: "Steam", Root: "Game/StreamingAssets", Files: []string{
+			"Game/StreamingAssets/Language/english.xml",
+		}},
+		{Name: "Scenario", Root: "Game/StreamingAssets", Files: []string{
+			// Scenario is walked before StreamingAssets.
+			"Game/Scenario/Mars/Language/english_mars.xml",
+			"Game/StreamingAssets/Language/english.xml",
+		}},
+		{Name: "Renamed", Root: "Assets", Files: []string{
+			"Assets/Language/english.xml",
+			"Assets/Scenario/Mars/Language/english_mars.xml",
+		}},
+		{Name: "Several", Error: true, Files: []string{
+			"A/StreamingAssets/Language/english.xml",
+			"B/StreamingAssets/Language/english.xml",
+		}},
+		{Name: "SeveralRenamed", Error: true, Files: []string{
+			"A/Language/english.xml",
+			"B/Language/english.xml",
+		}},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			fsys := make(fstest.MapFS)
+			for _, name := range tt.Files {
+				fsys[name] = &fstest.MapFile{Data: []byte("<Language/>")}
+			}
+			root, err := Find(fsys)
+			if tt.Error {
+				if err == nil {
+					t.Errorf("should fail, got %q", root)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if root != tt.Root {
+				t.Errorf("unexpected root %q", root)
+			}
+		})
+	}
+}
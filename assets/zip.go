@@ -0,0 +1,46 @@
+package assets
+
+import (
+	"archive/zip"
+	"io"
+	"sort"
+	"time"
+)
+
+// ModTime is modification time of all files in archives written by
+// WriteZip, so archives of the same files are identical.
+var ModTime = time.Date(2018, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// File in archive.
+type File struct {
+	Name string // slash-separated, like "StreamingAssets/Language/russian.xml"
+	Data []byte
+}
+
+// WriteZip writes deterministic zip archive of files to w.
+//
+// Files are sorted by name and have the same modification time and mode.
+func WriteZip(w io.Writer, files []File) error {
+	sorted := make([]File, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+	z := zip.NewWriter(w)
+	for _, f := range sorted {
+		h := &zip.FileHeader{
+			Name:     f.Name,
+			Method:   zip.Deflate,
+			Modified: ModTime,
+		}
+		h.SetMode(0644)
+		fw, err := z.CreateHeader(h)
+		if err != nil {
+			return err
+		}
+		if _, err = fw.Write(f.Data); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
@@ -0,0 +1,44 @@
+package assets
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+func TestWriteZip(t *testing.T) {
+	files := []File{
+		{Name: "StreamingAssets/Language/russian.xml", Data: []byte("<Language/>")},
+		{Name: "StreamingAssets/Language/german.xml", Data: []byte("<Language/>")},
+		{Name: "StreamingAssets/martian.json", Data: []byte("{}")},
+	}
+	a, b := new(bytes.Buffer), new(bytes.Buffer)
+	if err := WriteZip(a, files); err != nil {
+		t.Fatal(err)
+	}
+	// Reversed order should produce the same archive.
+	reversed := []File{files[2], files[1], files[0]}
+	if err := WriteZip(b, reversed); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.Bytes(), b.Bytes()) {
+		t.Fatal("archives are not identical")
+	}
+	r, err := zip.NewReader(bytes.NewReader(a.Bytes()), int64(a.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{
+		"StreamingAssets/Language/german.xml",
+		"StreamingAssets/Language/russian.xml",
+		"StreamingAssets/martian.json",
+	} {
+		f := r.File[i]
+		if f.Name != name {
+			t.Errorf("%s (got) != %s (expected)", f.Name, name)
+		}
+		if !f.Modified.Equal(ModTime) {
+			t.Errorf("unexpected modification time %s", f.Modified)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package check
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Allow describes intentional identical translations of different
+// msgids. Blank fields match anything.
+type Allow struct {
+	File      string   `mapstructure:"file"`      // glob of part, like "Keys"
+	IDs       []string `mapstructure:"ids"`       // msgids that may share translation
+	Str       string   `mapstructure:"str"`       // shared translation
+	Languages []string `mapstructure:"languages"` // codes
+}
+
+func (a Allow) allows(language, file, str string, ids []string) bool {
+	if a.File != "" {
+		if ok, err := path.Match(a.File, file); err != nil || !ok {
+			return false
+		}
+	}
+	if a.Str != "" && a.Str != str {
+		return false
+	}
+	if len(a.Languages) > 0 {
+		found := false
+		for _, l := range a.Languages {
+			if strings.EqualFold(l, language) {
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(a.IDs) > 0 {
+		for _, id := range ids {
+			found := false
+			for _, allowed := range a.IDs {
+				if allowed == id {
+					found = true
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CheckAmbiguity returns issues for translations that are shared by
+// entries with different msgids within the same part, like two gases
+// with the same name. Untranslated, fuzzy, identical to original and
+// translated with sentinel entries are ignored, as well as allowed ones.
+func CheckAmbiguity(language string, allow []Allow, entries resource.Entries) []Issue {
+	type key struct {
+		File string
+		Str  string
+	}
+	var (
+		keys   []key
+		byStr  = make(map[key]map[string]resource.Entries)
+		issues []Issue
+	)
+	for _, e := range entries {
+		if e.Str == "" || resource.IsSentinel(e.Str) || e.Fuzzy || e.Str == e.Original {
+			continue
+		}
+		k := key{File: e.File, Str: strings.TrimSpace(Visible(e.Str))}
+		if byStr[k] == nil {
+			byStr[k] = make(map[string]resource.Entries)
+			keys = append(keys, k)
+		}
+		byStr[k][e.ID] = append(byStr[k][e.ID], e)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].File != keys[j].File {
+			return keys[i].File < keys[j].File
+		}
+		return keys[i].Str < keys[j].Str
+	})
+Keys:
+	for _, k := range keys {
+		byID := byStr[k]
+		if len(byID) < 2 {
+			continue
+		}
+		var ids []string
+		for id := range byID {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, a := range allow {
+			if a.allows(language, k.File, k.Str, ids) {
+				continue Keys
+			}
+		}
+		var parts []string
+		for _, id := range ids {
+			parts = append(parts, fmt.Sprintf("%q (%s)", id, references(byID[id])))
+		}
+		e := byID[ids[1]][0]
+		issues = append(issues, Issue{
+			Rule:     "ambiguity",
+			Severity: Warning,
+			Language: language,
+			File:     e.File,
+			Context:  e.Context,
+			ID:       e.ID,
+			Str:      e.Str,
+			Message:  fmt.Sprintf("%q is translation of %d texts: %s", k.Str, len(ids), strings.Join(parts, ", ")),
+		})
+	}
+	return issues
+}
@@ -0,0 +1,48 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+func TestCheckAmbiguity(t *testing.T) {
+	entries := resource.Entries{
+		{File: "Gases", Context: "Gases.Volatiles", ID: "Volatiles", Original: "Volatiles", Str: "Летучие"},
+		{File: "Gases", Context: "Gases.Pollutant", ID: "Pollutant", Original: "Pollutant", Str: "Летучие "},
+		{File: "Gases", Context: "Gases.Water", ID: "Water", Original: "Water", Str: "Вода"},
+		{File: "Gases", Context: "Gases.Steam", ID: "Steam", Original: "Steam", Str: "Вода", Fuzzy: true},
+		{File: "Keys", Context: "Keys.Return", ID: "Return", Original: "Return", Str: "Ввод"},
+		{File: "Keys", Context: "Keys.Enter", ID: "Enter", Original: "Enter", Str: "Ввод"},
+		{File: "Keys", Context: "Keys.KeypadEnter", ID: "Keypad Enter", Original: "Keypad Enter", Str: "<size=40%>Ввод</size>"},
+		{File: "Interface", Context: "Interface.Enter", ID: "Enter", Original: "Enter", Str: "Войти"},
+		{File: "Interface", Context: "Interface.Login", ID: "Login", Original: "Login", Str: "Войти"},
+		{File: "Things", Context: "Things.ItemFlour", ID: "Flour", Original: "Flour", Str: "Мука"},
+		{File: "Reagents", Context: "Reagents.Flour", ID: "Flour Reagent", Original: "Flour Reagent", Str: "Мука"},
+	}
+	issues := CheckAmbiguity("RU", nil, entries)
+	if len(issues) != 3 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	expected := `"Летучие" is translation of 2 texts: "Pollutant" (Gases.Pollutant), "Volatiles" (Gases.Volatiles)`
+	if issues[0].Message != expected || issues[0].Context != "Gases.Volatiles" {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if issues[2].File != "Keys" || issues[2].Message[:len(`"Ввод" is translation of 3`)] != `"Ввод" is translation of 3` {
+		t.Errorf("unexpected issue %+v", issues[2])
+	}
+	allow := []Allow{
+		{File: "Keys", IDs: []string{"Enter", "Keypad Enter", "Return"}},
+		{Str: "Войти", Languages: []string{"ru"}},
+	}
+	if issues = CheckAmbiguity("RU", allow, entries); len(issues) != 1 || issues[0].File != "Gases" {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+	if issues = CheckAmbiguity("UK", allow, entries); len(issues) != 2 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+	allow[0].IDs = allow[0].IDs[:2]
+	if issues = CheckAmbiguity("RU", allow, entries); len(issues) != 2 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
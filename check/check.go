@@ -0,0 +1,50 @@
+// Package check implements validation of translations.
+package check
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// Severities of issues.
+const (
+	Error   = "error"
+	Warning = "warning"
+)
+
+// Issue is problem found in translated entry.
+type Issue struct {
+	Rule     string `json:"rule"` // like "font"
+	Severity string `json:"severity"`
+	Language string `json:"language"` // code
+	File     string `json:"file"`     // like Entry.File
+	Context  string `json:"context,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Str      string `json:"str,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Sort sorts issues by language, file and context.
+func Sort(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Language != b.Language {
+			return a.Language < b.Language
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Context < b.Context
+	})
+}
+
+// WriteText writes issues as table.
+func WriteText(w io.Writer, issues []Issue) error {
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, i := range issues {
+		fmt.Fprintf(t, "%s\t%s\t%s\t%s\t%s\t%s\n", i.Severity, i.Rule, i.Language, i.File, i.Context, i.Message)
+	}
+	return t.Flush()
+}
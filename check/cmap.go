@@ -0,0 +1,190 @@
+package check
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode"
+)
+
+var errBadFont = errors.New("bad font data")
+
+func u16(b []byte, off int) (int, error) {
+	if off < 0 || off+2 > len(b) {
+		return 0, errBadFont
+	}
+	return int(binary.BigEndian.Uint16(b[off:])), nil
+}
+
+func u32(b []byte, off int) (int, error) {
+	if off < 0 || off+4 > len(b) {
+		return 0, errBadFont
+	}
+	return int(binary.BigEndian.Uint32(b[off:])), nil
+}
+
+// table returns contents of table of TrueType or OpenType font by tag,
+// like "cmap".
+func table(font []byte, tag string) ([]byte, error) {
+	numTables, err := u16(font, 4)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(font) {
+			return nil, errBadFont
+		}
+		if string(font[rec:rec+4]) != tag {
+			continue
+		}
+		off, _ := u32(font, rec+8)
+		length, _ := u32(font, rec+12)
+		if off+length > len(font) {
+			return nil, errBadFont
+		}
+		return font[off : off+length], nil
+	}
+	return nil, fmt.Errorf("no %s table", tag)
+}
+
+// ReadCmap returns characters mapped to glyphs by cmap table of
+// TrueType or OpenType font, using unicode subtable of format 4 or 12.
+func ReadCmap(font []byte) (Charset, error) {
+	var ranges []Range
+	if err := readGlyphs(font, func(r rune, glyph int) {
+		if n := len(ranges); n > 0 && ranges[n-1].Hi+1 == r {
+			ranges[n-1].Hi = r
+		} else {
+			ranges = append(ranges, Range{Lo: r, Hi: r})
+		}
+	}); err != nil {
+		return nil, err
+	}
+	return NewCharset(ranges...), nil
+}
+
+// readGlyphs calls fn for every character mapped to glyph by cmap table
+// of font, see ReadCmap.
+func readGlyphs(font []byte, fn func(r rune, glyph int)) error {
+	cmap, err := table(font, "cmap")
+	if err != nil {
+		return err
+	}
+	numTables, err := u16(cmap, 2)
+	if err != nil {
+		return err
+	}
+	var best, bestScore int
+	for i := 0; i < numTables; i++ {
+		rec := 4 + 8*i
+		platform, err := u16(cmap, rec)
+		if err != nil {
+			return err
+		}
+		encoding, _ := u16(cmap, rec+2)
+		off, err := u32(cmap, rec+4)
+		if err != nil {
+			return err
+		}
+		format, err := u16(cmap, off)
+		if err != nil {
+			return err
+		}
+		score := 0
+		switch {
+		case format == 12 && (platform == 0 || platform == 3 && encoding == 10):
+			score = 2
+		case format == 4 && (platform == 0 || platform == 3 && encoding == 1):
+			score = 1
+		}
+		if score > bestScore {
+			best, bestScore = off, score
+		}
+	}
+	if bestScore == 0 {
+		return errors.New("no unicode cmap subtable of format 4 or 12")
+	}
+	format, _ := u16(cmap, best)
+	if format == 12 {
+		return readCmap12(cmap[best:], fn)
+	}
+	return readCmap4(cmap[best:], fn)
+}
+
+func readCmap4(t []byte, fn func(r rune, glyph int)) error {
+	segCountX2, err := u16(t, 6)
+	if err != nil {
+		return err
+	}
+	var (
+		segCount      = segCountX2 / 2
+		endCodes      = 14
+		startCodes    = endCodes + segCountX2 + 2
+		idDeltas      = startCodes + segCountX2
+		idRangeOffset = idDeltas + segCountX2
+	)
+	for i := 0; i < segCount; i++ {
+		end, err := u16(t, endCodes+2*i)
+		if err != nil {
+			return err
+		}
+		start, err := u16(t, startCodes+2*i)
+		if err != nil {
+			return err
+		}
+		delta, err := u16(t, idDeltas+2*i)
+		if err != nil {
+			return err
+		}
+		rangeOffset, err := u16(t, idRangeOffset+2*i)
+		if err != nil {
+			return err
+		}
+		if start == 0xFFFF {
+			continue
+		}
+		for c := start; c <= end; c++ {
+			glyph := (c + delta) & 0xFFFF
+			if rangeOffset != 0 {
+				addr := idRangeOffset + 2*i + rangeOffset + 2*(c-start)
+				if glyph, err = u16(t, addr); err != nil {
+					return err
+				}
+				if glyph != 0 {
+					glyph = (glyph + delta) & 0xFFFF
+				}
+			}
+			if glyph != 0 {
+				fn(rune(c), glyph)
+			}
+		}
+	}
+	return nil
+}
+
+func readCmap12(t []byte, fn func(r rune, glyph int)) error {
+	numGroups, err := u32(t, 12)
+	if err != nil {
+		return err
+	}
+	if 16+12*numGroups > len(t) {
+		return fmt.Errorf("%v: %d groups", errBadFont, numGroups)
+	}
+	for i := 0; i < numGroups; i++ {
+		g := 16 + 12*i
+		start, _ := u32(t, g)
+		end, _ := u32(t, g+4)
+		glyph, _ := u32(t, g+8)
+		if end > unicode.MaxRune {
+			return fmt.Errorf("%v: group %d", errBadFont, i)
+		}
+		for c := start; c <= end; c++ {
+			// Characters mapped to missing glyph are skipped.
+			if g := glyph + c - start; g != 0 {
+				fn(rune(c), g)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Variant is translation of msgid with entries that use it.
+type Variant struct {
+	Str     string
+	Entries resource.Entries
+}
+
+// Group is entries with the same msgid that are translated differently.
+type Group struct {
+	ID       string
+	Variants []Variant // most used first
+}
+
+// Majority returns the most used translation, or false if several
+// translations are used equally.
+func (g Group) Majority() (string, bool) {
+	if len(g.Variants) > 1 && len(g.Variants[0].Entries) == len(g.Variants[1].Entries) {
+		return "", false
+	}
+	return g.Variants[0].Str, true
+}
+
+// Propagate returns entries that differ from str with str as fuzzy
+// translation.
+func (g Group) Propagate(str string) resource.Entries {
+	var propagated resource.Entries
+	for _, v := range g.Variants {
+		if v.Str == str {
+			continue
+		}
+		for _, e := range v.Entries {
+			e.Str = str
+			e.Fuzzy = true
+			propagated = append(propagated, e)
+		}
+	}
+	return propagated
+}
+
+// Inconsistent returns groups of translated entries with the same msgid
+// and different translations, sorted by msgid. Untranslated, fuzzy and
+// translated with sentinel entries are ignored.
+func Inconsistent(entries resource.Entries) []Group {
+	byID := make(map[string]map[string]resource.Entries)
+	for _, e := range entries {
+		if e.Str == "" || resource.IsSentinel(e.Str) || e.Fuzzy {
+			continue
+		}
+		if byID[e.ID] == nil {
+			byID[e.ID] = make(map[string]resource.Entries)
+		}
+		byID[e.ID][e.Str] = append(byID[e.ID][e.Str], e)
+	}
+	var groups []Group
+	for id, variants := range byID {
+		if len(variants) < 2 {
+			continue
+		}
+		g := Group{ID: id}
+		for str, entries := range variants {
+			g.Variants = append(g.Variants, Variant{Str: str, Entries: entries})
+		}
+		sort.Slice(g.Variants, func(i, j int) bool {
+			a, b := g.Variants[i], g.Variants[j]
+			if len(a.Entries) != len(b.Entries) {
+				return len(a.Entries) > len(b.Entries)
+			}
+			return a.Str < b.Str
+		})
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return groups[i].ID < groups[j].ID
+	})
+	return groups
+}
+
+func references(entries resource.Entries) string {
+	var refs []string
+	for _, e := range entries {
+		ref := e.Context
+		if ref == "" {
+			ref = e.File
+		}
+		refs = append(refs, ref)
+	}
+	return strings.Join(refs, ", ")
+}
+
+// CheckConsistency returns issues for groups of entries with the same
+// msgid that are translated differently, one for each group at the first
+// entry that differs from majority.
+func CheckConsistency(language string, entries resource.Entries) []Issue {
+	var issues []Issue
+	for _, g := range Inconsistent(entries) {
+		var parts []string
+		for _, v := range g.Variants {
+			parts = append(parts, fmt.Sprintf("%q (%d: %s)", v.Str, len(v.Entries), references(v.Entries)))
+		}
+		msg := fmt.Sprintf("%d translations of %q: %s", len(g.Variants), g.ID, strings.Join(parts, ", "))
+		e := g.Variants[1].Entries[0]
+		issues = append(issues, Issue{
+			Rule:     "consistency",
+			Severity: Warning,
+			Language: language,
+			File:     e.File,
+			Context:  e.Context,
+			ID:       e.ID,
+			Str:      e.Str,
+			Message:  msg,
+		})
+	}
+	return issues
+}
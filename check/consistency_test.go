@@ -0,0 +1,49 @@
+package check
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+func TestCheckConsistency(t *testing.T) {
+	entries := resource.Entries{
+		{File: "Things", Context: "Things.ItemFlour", ID: "Flour", Str: "Мука"},
+		{File: "Reagents", Context: "Reagents.Flour", ID: "Flour", Str: "Мука"},
+		{File: "Interface", Context: "Interface.Flour", ID: "Flour", Str: "Мучка"},
+		{File: "Interface", Context: "Interface.Fuzzy", ID: "Flour", Str: "Мучица", Fuzzy: true},
+		{File: "Things", Context: "Things.ItemMilk", ID: "Milk", Str: "Молоко"},
+		{File: "Reagents", Context: "Reagents.Milk", ID: "Milk", Str: "Молочко"},
+		{File: "Reagents", Context: "Reagents.Egg", ID: "Egg", Str: "Яйцо"},
+		{File: "Things", Context: "Things.ItemEgg", ID: "Egg", Str: ""},
+	}
+	groups := Inconsistent(entries)
+	if len(groups) != 2 || groups[0].ID != "Flour" || groups[1].ID != "Milk" {
+		t.Fatalf("unexpected groups %+v", groups)
+	}
+	if str, ok := groups[0].Majority(); !ok || str != "Мука" {
+		t.Errorf("unexpected majority %q", str)
+	}
+	if _, ok := groups[1].Majority(); ok {
+		t.Error("should be no majority")
+	}
+	translations := groups[0].Propagate("Мука")
+	if len(translations) != 1 || translations[0].Context != "Interface.Flour" || !translations[0].Fuzzy {
+		t.Errorf("unexpected translations %+v", translations)
+	}
+	if translations = groups[1].Propagate("Молоко"); len(translations) != 1 || translations[0].Context != "Reagents.Milk" {
+		t.Errorf("unexpected translations %+v", translations)
+	}
+	issues := CheckConsistency("RU", entries)
+	if len(issues) != 2 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	expected := `2 translations of "Flour": "Мука" (2: Things.ItemFlour, Reagents.Flour), "Мучка" (1: Interface.Flour)`
+	if issues[0].Message != expected || issues[0].Context != "Interface.Flour" {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if !strings.HasPrefix(issues[1].Message, `2 translations of "Milk"`) {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+}
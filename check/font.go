@@ -0,0 +1,172 @@
+package check
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Range is inclusive range of characters.
+type Range struct {
+	Lo, Hi rune
+}
+
+// Charset is set of characters supported by font.
+type Charset []Range
+
+// NewCharset returns charset of ranges, merging overlapping ones.
+func NewCharset(ranges ...Range) Charset {
+	c := append(Charset{}, ranges...)
+	sort.Slice(c, func(i, j int) bool {
+		return c[i].Lo < c[j].Lo
+	})
+	var merged Charset
+	for _, r := range c {
+		if n := len(merged); n > 0 && r.Lo <= merged[n-1].Hi+1 {
+			if r.Hi > merged[n-1].Hi {
+				merged[n-1].Hi = r.Hi
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Add returns charset with added ranges.
+func (c Charset) Add(ranges ...Range) Charset {
+	return NewCharset(append(append(Charset{}, c...), ranges...)...)
+}
+
+// Contains reports whether charset contains character.
+func (c Charset) Contains(r rune) bool {
+	i := sort.Search(len(c), func(i int) bool {
+		return c[i].Hi >= r
+	})
+	return i < len(c) && c[i].Lo <= r
+}
+
+func parseCodePoint(s string) (rune, error) {
+	s = strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(s)), "U+")
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		return 0, fmt.Errorf("bad code point %q", s)
+	}
+	return rune(v), nil
+}
+
+// ParseRanges parses ranges of hex code points, like "0400-04FF",
+// "U+00A0-U+00FF" or single "2116".
+func ParseRanges(list []string) (Charset, error) {
+	var ranges []Range
+	for _, s := range list {
+		parts := strings.SplitN(s, "-", 2)
+		lo, err := parseCodePoint(parts[0])
+		if err != nil {
+			return nil, err
+		}
+		hi := lo
+		if len(parts) == 2 {
+			if hi, err = parseCodePoint(parts[1]); err != nil {
+				return nil, err
+			}
+		}
+		if hi < lo {
+			return nil, fmt.Errorf("bad range %q", s)
+		}
+		ranges = append(ranges, Range{Lo: lo, Hi: hi})
+	}
+	return NewCharset(ranges...), nil
+}
+
+// CharsetOf returns charset of characters in string.
+func CharsetOf(s string) Charset {
+	var ranges []Range
+	for _, r := range s {
+		ranges = append(ranges, Range{Lo: r, Hi: r})
+	}
+	return NewCharset(ranges...)
+}
+
+// Missing returns sorted unique characters of s that are not in charset.
+// Control and space characters are ignored.
+func (c Charset) Missing(s string) []rune {
+	seen := make(map[rune]bool)
+	var missing []rune
+	for _, r := range s {
+		if unicode.IsControl(r) || unicode.IsSpace(r) || seen[r] || c.Contains(r) {
+			continue
+		}
+		seen[r] = true
+		missing = append(missing, r)
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		return missing[i] < missing[j]
+	})
+	return missing
+}
+
+// Fonts are charsets by font name, like "russian".
+type Fonts map[string]Charset
+
+// Suggest returns name of font that contains all characters of s, or
+// blank string.
+func (f Fonts) Suggest(s string) string {
+	var names []string
+	for name := range f {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if len(f[name].Missing(s)) == 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+func formatRunes(runes []rune) string {
+	var parts []string
+	for _, r := range runes {
+		parts = append(parts, fmt.Sprintf("%q (U+%04X)", r, r))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// CheckFont returns issues for translated entries with characters
+// that are not supported by font of language.
+func CheckFont(language, font string, fonts Fonts, entries resource.Entries) []Issue {
+	charset, ok := fonts[font]
+	if !ok {
+		return nil
+	}
+	var issues []Issue
+	for _, e := range entries {
+		if e.Str == "" || resource.IsSentinel(e.Str) {
+			continue
+		}
+		missing := charset.Missing(e.Str)
+		if len(missing) == 0 {
+			continue
+		}
+		msg := fmt.Sprintf("font %s has no %s", font, formatRunes(missing))
+		if suggested := fonts.Suggest(e.Str); suggested != "" {
+			msg += fmt.Sprintf(", use %s", suggested)
+		}
+		issues = append(issues, Issue{
+			Rule:     "font",
+			Severity: Error,
+			Language: language,
+			File:     e.File,
+			Context:  e.Context,
+			ID:       e.ID,
+			Str:      e.Str,
+			Message:  msg,
+		})
+	}
+	return issues
+}
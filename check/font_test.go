@@ -0,0 +1,130 @@
+package check
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+type be []byte
+
+func (b be) u16(v ...int) be {
+	for _, x := range v {
+		var buf [2]byte
+		binary.BigEndian.PutUint16(buf[:], uint16(x))
+		b = append(b, buf[:]...)
+	}
+	return b
+}
+
+func (b be) u32(v ...int) be {
+	for _, x := range v {
+		var buf [4]byte
+		binary.BigEndian.PutUint32(buf[:], uint32(x))
+		b = append(b, buf[:]...)
+	}
+	return b
+}
+
+// buildFont returns font with tables by tags.
+func buildFont(tags []string, tables ...be) []byte {
+	font := be{}.u32(0x00010000).u16(len(tags), 16, 0, 0)
+	off := 12 + 16*len(tags)
+	for i, tag := range tags {
+		font = append(font, tag...)
+		font = font.u32(0, off, len(tables[i]))
+		off += len(tables[i])
+	}
+	for _, t := range tables {
+		font = append(font, t...)
+	}
+	return font
+}
+
+// testFont returns font with single cmap subtable.
+func testFont(platform, encoding int, subtable be) []byte {
+	cmap := be{}.u16(0, 1).u16(platform, encoding).u32(12)
+	return buildFont([]string{"cmap"}, append(cmap, subtable...))
+}
+
+func TestReadCmap(t *testing.T) {
+	t.Run("Format4", func(t *testing.T) {
+		// Segments: A-C by delta, Ё-ё (U+0401-U+0403) by glyph array with
+		// missing U+0402, and final 0xFFFF.
+		sub := be{}.u16(4, 0, 0, 3*2, 0, 0, 0).
+			u16(0x43, 0x403, 0xFFFF). // end codes
+			u16(0).                   // reserved pad
+			u16(0x41, 0x401, 0xFFFF). // start codes
+			u16(-0x40, 0, 1).         // id deltas
+			u16(0, 4, 0).             // id range offsets
+			u16(5, 0, 6)              // glyph array
+		c, err := ReadCmap(testFont(3, 1, sub))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for r, expected := range map[rune]bool{
+			'A': true, 'C': true, 'D': false,
+			'Ё': true, 'Ђ': false, 'Ѓ': true, 0xFFFF: false,
+		} {
+			if c.Contains(r) != expected {
+				t.Errorf("Contains(%q) != %v", r, expected)
+			}
+		}
+	})
+	t.Run("Format12", func(t *testing.T) {
+		sub := be{}.u16(12, 0).u32(16+12*2, 0, 2).
+			u32(0x20, 0x7E, 1).
+			u32(0x1F600, 0x1F64F, 100)
+		c, err := ReadCmap(testFont(3, 10, sub))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !c.Contains('~') || !c.Contains('😀') || c.Contains('ж') {
+			t.Errorf("unexpected charset %v", c)
+		}
+	})
+	t.Run("Bad", func(t *testing.T) {
+		if _, err := ReadCmap([]byte("not a font")); err == nil {
+			t.Error("should fail")
+		}
+	})
+}
+
+func TestCheckFont(t *testing.T) {
+	basic, err := ParseRanges([]string{"0020-007E"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	russian, err := ParseRanges([]string{"U+0020-U+007E", "0400-045F", "2116"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fonts := Fonts{
+		"english": basic,
+		"russian": russian,
+	}
+	entries := resource.Entries{
+		{File: "Keys", Context: "Keys.Tab", Str: "Tab"},
+		{File: "Things", Context: "Things.Flour", Str: "Мука №1\n"},
+		{File: "Things", Context: "Things.Milk", Str: "Milch ü"},
+		{File: "Things", Context: "Things.Blank", Str: resource.Blank},
+	}
+	issues := CheckFont("DE", "english", fonts, entries)
+	if len(issues) != 2 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if !strings.HasSuffix(issues[0].Message, ", use russian") || issues[0].Context != "Things.Flour" {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if !strings.Contains(issues[1].Message, "U+00FC") || strings.Contains(issues[1].Message, "use") {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+	if issues := CheckFont("RU", "russian", fonts, entries[:2]); len(issues) != 0 {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+	if _, err = ParseRanges([]string{"04FF-0400"}); err == nil {
+		t.Error("should fail")
+	}
+}
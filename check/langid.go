@@ -0,0 +1,171 @@
+package check
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Identifier guesses language of text by character trigrams of words,
+// with naive Bayes classifier over profiles of languages, see Train.
+type Identifier struct {
+	profiles   map[string]*profile // by language
+	vocabulary map[string]bool     // trigrams of all profiles
+}
+
+type profile struct {
+	counts map[string]int
+	total  int
+}
+
+// NewIdentifier returns identifier without profiles.
+func NewIdentifier() *Identifier {
+	return &Identifier{
+		profiles:   make(map[string]*profile),
+		vocabulary: make(map[string]bool),
+	}
+}
+
+// normalize returns lower-case words of letters of visible text, without
+// placeholders.
+func normalize(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(placeholder.ReplaceAllString(Visible(s), " ")), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// trigrams returns character trigrams of words padded with spaces, so
+// beginnings and endings of words are distinguished.
+func trigrams(s string) []string {
+	var grams []string
+	for _, w := range normalize(s) {
+		runes := []rune(" " + w + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+3]))
+		}
+	}
+	return grams
+}
+
+// Train adds texts to profile of language, like "RU".
+func (id *Identifier) Train(language string, texts ...string) {
+	p := id.profiles[language]
+	if p == nil {
+		p = &profile{counts: make(map[string]int)}
+		id.profiles[language] = p
+	}
+	for _, s := range texts {
+		for _, g := range trigrams(s) {
+			p.counts[g]++
+			p.total++
+			id.vocabulary[g] = true
+		}
+	}
+}
+
+// Knows reports whether identifier has profile of language.
+func (id *Identifier) Knows(language string) bool {
+	return id.profiles[language] != nil
+}
+
+// Score is probability of text being in language.
+type Score struct {
+	Language    string
+	Probability float64
+}
+
+// Identify returns scores of all known languages for text, the most
+// probable first, or nil if text has no letters or no languages are known.
+func (id *Identifier) Identify(s string) []Score {
+	grams := trigrams(s)
+	if len(grams) == 0 || len(id.profiles) == 0 {
+		return nil
+	}
+	var (
+		scores = make([]Score, 0, len(id.profiles))
+		v      = float64(len(id.vocabulary))
+		max    = math.Inf(-1)
+	)
+	for language, p := range id.profiles {
+		// Log-likelihood with add-one smoothing and uniform prior.
+		var logP float64
+		for _, g := range grams {
+			logP += math.Log(float64(p.counts[g]+1) / (float64(p.total) + v))
+		}
+		if logP > max {
+			max = logP
+		}
+		scores = append(scores, Score{Language: language, Probability: logP})
+	}
+	var sum float64
+	for i := range scores {
+		scores[i].Probability = math.Exp(scores[i].Probability - max)
+		sum += scores[i].Probability
+	}
+	for i := range scores {
+		scores[i].Probability /= sum
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		a, b := scores[i], scores[j]
+		if a.Probability != b.Probability {
+			return a.Probability > b.Probability
+		}
+		return a.Language < b.Language
+	})
+	return scores
+}
+
+// minLanguageLetters is minimal count of letters of translations that are
+// identified, shorter ones are too ambiguous.
+const minLanguageLetters = 16
+
+func letters(s string) int {
+	n := 0
+	for _, w := range normalize(s) {
+		n += len([]rune(w))
+	}
+	return n
+}
+
+// CheckLanguage returns issues for translations of language that are
+// identified as other language, like english, with probability of at
+// least threshold. Translations that are identical to original are
+// intentional and ignored, as well as short ones. No issues are returned
+// if language is not known by identifier.
+func CheckLanguage(language string, id *Identifier, threshold float64, entries resource.Entries) []Issue {
+	if !id.Knows(language) {
+		return nil
+	}
+	var issues []Issue
+	for _, e := range entries {
+		if e.Str == "" || resource.IsSentinel(e.Str) || e.Fuzzy || e.Str == e.Original {
+			continue
+		}
+		if letters(e.Str) < minLanguageLetters {
+			continue
+		}
+		scores := id.Identify(e.Str)
+		if len(scores) == 0 {
+			continue
+		}
+		best := scores[0]
+		if best.Language == language || best.Probability < threshold {
+			continue
+		}
+		issues = append(issues, Issue{
+			Rule:     "language",
+			Severity: Warning,
+			Language: language,
+			File:     e.File,
+			Context:  e.Context,
+			ID:       e.ID,
+			Str:      e.Str,
+			Message:  fmt.Sprintf("looks like %s (%.0f%% confidence), not %s", best.Language, best.Probability*100, language),
+		})
+	}
+	return issues
+}
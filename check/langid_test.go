@@ -0,0 +1,93 @@
+package check
+
+import (
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+func testIdentifier() *Identifier {
+	id := NewIdentifier()
+	id.Train("EN",
+		"Press {KEY:Jetpack} to toggle the jetpack on and off.",
+		"The furnace is used to smelt ores into ingots.",
+		"Keep an eye on the pressure of the atmosphere in your base.",
+		"Plants need light, water and carbon dioxide to grow.",
+		"The solar panel generates power when it is facing the sun.",
+		"<color=red>Warning:</color> the temperature is too high for this device.",
+	)
+	id.Train("RU",
+		"Нажмите {KEY:Jetpack}, чтобы включить или выключить реактивный ранец.",
+		"Печь используется для выплавки слитков из руды.",
+		"Следите за давлением атмосферы на своей базе.",
+		"Растениям для роста нужны свет, вода и углекислый газ.",
+		"Солнечная панель вырабатывает энергию, когда повёрнута к солнцу.",
+		"<color=red>Внимание:</color> температура слишком высока для этого устройства.",
+	)
+	id.Train("DE",
+		"Drücke {KEY:Jetpack}, um das Jetpack ein- und auszuschalten.",
+		"Der Schmelzofen wird verwendet, um Erze zu Barren zu schmelzen.",
+		"Behalte den Druck der Atmosphäre in deiner Basis im Auge.",
+		"Pflanzen brauchen Licht, Wasser und Kohlendioxid zum Wachsen.",
+		"Das Solarpanel erzeugt Strom, wenn es zur Sonne ausgerichtet ist.",
+		"<color=red>Warnung:</color> die Temperatur ist zu hoch für dieses Gerät.",
+	)
+	return id
+}
+
+func TestIdentifier(t *testing.T) {
+	id := testIdentifier()
+	for s, expected := range map[string]string{
+		"The pressure of the furnace is too high.":     "EN",
+		"Давление в печи слишком высокое.":             "RU",
+		"Der Druck im Schmelzofen ist zu hoch.":        "DE",
+		"<size=40%>Plants need water and light</size>": "EN",
+	} {
+		scores := id.Identify(s)
+		if len(scores) != 3 {
+			t.Fatalf("unexpected scores %+v", scores)
+		}
+		if scores[0].Language != expected {
+			t.Errorf("%q identified as %s, expected %s", s, scores[0].Language, expected)
+		}
+		var sum float64
+		for _, score := range scores {
+			sum += score.Probability
+		}
+		if sum < 0.999 || sum > 1.001 {
+			t.Errorf("sum of probabilities %f", sum)
+		}
+	}
+	if scores := id.Identify("{0} 100%"); scores != nil {
+		t.Errorf("unexpected scores %+v", scores)
+	}
+	if !id.Knows("RU") || id.Knows("FR") {
+		t.Error("unexpected known languages")
+	}
+}
+
+func TestCheckLanguage(t *testing.T) {
+	id := testIdentifier()
+	entries := resource.Entries{
+		{File: "Tips", ID: "Keep an eye on the pressure", Original: "Keep an eye on the pressure", Str: "Следите за давлением на базе"},
+		{File: "Tips", ID: "Plants need light to grow", Original: "Plants need light to grow", Str: "Plants need some light to grow"},
+		{File: "Tips", ID: "The furnace smelts ores", Original: "The furnace smelts ores", Str: "Der Schmelzofen schmilzt Erze"},
+		{File: "Tips", ID: "The furnace smelts ores.", Original: "The furnace smelts ores.", Str: "The furnace smelts ores."},
+		{File: "Tips", ID: "Solar panel", Original: "Solar panel", Str: "Solar panels"},
+		{File: "Tips", ID: "Warning", Original: "Warning", Str: "The temperature is too high", Fuzzy: true},
+		{File: "Tips", ID: "Power", Original: "Power", Str: resource.Same},
+	}
+	issues := CheckLanguage("RU", id, 0.9, entries)
+	if len(issues) != 2 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if issues[0].ID != "Plants need light to grow" || issues[0].Rule != "language" || issues[0].Message[:len("looks like EN")] != "looks like EN" {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if issues[1].Message[:len("looks like DE")] != "looks like DE" {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+	if issues = CheckLanguage("FR", id, 0.9, entries); issues != nil {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+}
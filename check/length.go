@@ -0,0 +1,96 @@
+package check
+
+import (
+	"fmt"
+	"math"
+	"path"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Limit is limit of translation length for entries with context that
+// matches pattern. Zero values are not checked.
+type Limit struct {
+	Match    string  `mapstructure:"match"`    // glob of context, like "Keys.*"
+	Max      int     `mapstructure:"max"`      // characters
+	Ratio    float64 `mapstructure:"ratio"`    // of english length
+	Width    float64 `mapstructure:"width"`    // pixels, requires font metrics
+	Size     float64 `mapstructure:"size"`     // font size in pixels for width
+	Severity string  `mapstructure:"severity"` // warning by default
+}
+
+func (l Limit) matches(context string) bool {
+	ok, err := path.Match(l.Match, context)
+	return err == nil && ok
+}
+
+// richText matches Unity rich text tags, like <color=red> or </size>.
+var richText = regexp.MustCompile(`</?[a-z]+(=[^>]*)?>`)
+
+// Visible returns text without rich text tags.
+func Visible(s string) string {
+	return richText.ReplaceAllString(s, "")
+}
+
+// CheckLength returns issues for translated entries that exceed the
+// first limit with matching context. Width limits are checked only with
+// metrics of font of language.
+func CheckLength(language string, limits []Limit, metrics *Metrics, entries resource.Entries) []Issue {
+	var issues []Issue
+	for _, e := range entries {
+		if e.Str == "" || resource.IsSentinel(e.Str) {
+			continue
+		}
+		var (
+			limit Limit
+			found bool
+		)
+		for _, l := range limits {
+			if l.matches(e.Context) {
+				limit, found = l, true
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+		var (
+			str      = Visible(e.Str)
+			length   = utf8.RuneCountInString(str)
+			original = utf8.RuneCountInString(Visible(e.Original))
+			msg      string
+		)
+		switch {
+		case limit.Max > 0 && length > limit.Max:
+			msg = fmt.Sprintf("length %d exceeds %d", length, limit.Max)
+		case limit.Ratio > 0 && original > 0 && length > int(math.Ceil(limit.Ratio*float64(original))):
+			msg = fmt.Sprintf("length %d is %.1f× of english %d, limit is %.1f×",
+				length, float64(length)/float64(original), original, limit.Ratio,
+			)
+		case limit.Width > 0 && limit.Size > 0 && metrics != nil:
+			if w := metrics.Width(str, limit.Size); w > limit.Width {
+				msg = fmt.Sprintf("width %.0fpx exceeds %.0fpx", w, limit.Width)
+			}
+		}
+		if msg == "" {
+			continue
+		}
+		severity := limit.Severity
+		if severity == "" {
+			severity = Warning
+		}
+		issues = append(issues, Issue{
+			Rule:     "length",
+			Severity: severity,
+			Language: language,
+			File:     e.File,
+			Context:  e.Context,
+			ID:       e.ID,
+			Str:      e.Str,
+			Message:  msg,
+		})
+	}
+	return issues
+}
@@ -0,0 +1,78 @@
+package check
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+func TestReadMetrics(t *testing.T) {
+	// Glyphs: 0 missing, 1 'A' and 2 'B' with 2 metrics, so 'B' has
+	// advance of 'A'.
+	cmap := be{}.u16(0, 1).u16(3, 10).u32(12).
+		u16(12, 0).u32(16+12, 0, 1).
+		u32(0x41, 0x42, 1)
+	head := make(be, 18).u16(1000).u16(make([]int, 17)...)
+	hhea := make(be, 34).u16(2)
+	hmtx := be{}.u16(500, 0, 600, 0)
+	m, err := ReadMetrics(buildFont(
+		[]string{"cmap", "head", "hhea", "hmtx"},
+		cmap, head, hhea, hmtx,
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s, expected := range map[string]float64{
+		"":        0,
+		"AB":      12,
+		"AB\nABC": 17,
+	} {
+		if got := m.Width(s, 10); math.Abs(got-expected) > 1e-9 {
+			t.Errorf("Width(%q) = %v, expected %v", s, got, expected)
+		}
+	}
+	if _, err = ReadMetrics(testFont(3, 10, be{}.u16(12, 0).u32(16, 0, 0))); err == nil {
+		t.Error("should fail without head")
+	}
+}
+
+func TestCheckLength(t *testing.T) {
+	limits := []Limit{
+		{Match: "Keys.Esc", Max: 4, Severity: Error},
+		{Match: "Keys.*", Ratio: 2},
+		{Match: "Interface.*", Width: 70, Size: 10},
+	}
+	entries := resource.Entries{
+		{File: "Keys", Context: "Keys.Esc", Original: "Escape", Str: "Выход"},
+		{File: "Keys", Context: "Keys.Tab", Original: "Tab", Str: "Таб"},
+		{File: "Keys", Context: "Keys.Space", Original: "Space", Str: "Клавиша пробел"},
+		{File: "Keys", Context: "Keys.Enter", Original: "Enter", Str: "<b>Ввод</b>"},
+		{File: "Interface", Context: "Interface.Ok", Original: "OK", Str: "Хорошо, хорошо"},
+		{File: "Things", Context: "Things.Flour", Original: "Flour", Str: "Очень длинная мука"},
+	}
+	issues := CheckLength("RU", limits, nil, entries)
+	if len(issues) != 2 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if issues[0].Context != "Keys.Esc" || issues[0].Severity != Error {
+		t.Errorf("unexpected issue %+v", issues[0])
+	}
+	if issues[1].Context != "Keys.Space" || issues[1].Severity != Warning ||
+		!strings.Contains(issues[1].Message, "2.8×") {
+		t.Errorf("unexpected issue %+v", issues[1])
+	}
+	m := &Metrics{
+		UnitsPerEm: 1000,
+		advances:   map[rune]int{'Х': 800},
+		missing:    500,
+	}
+	issues = CheckLength("RU", limits, m, entries)
+	if len(issues) != 3 || issues[2].Context != "Interface.Ok" {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	if issues[2].Message != "width 73px exceeds 70px" {
+		t.Errorf("unexpected message %q", issues[2].Message)
+	}
+}
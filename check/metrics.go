@@ -0,0 +1,86 @@
+package check
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Metrics are horizontal metrics of TrueType or OpenType font.
+type Metrics struct {
+	UnitsPerEm int
+	advances   map[rune]int
+	missing    int // advance of missing glyph
+}
+
+// ReadMetrics reads advance widths of characters from "head", "hhea",
+// "hmtx" and "cmap" tables of font.
+func ReadMetrics(font []byte) (*Metrics, error) {
+	head, err := table(font, "head")
+	if err != nil {
+		return nil, err
+	}
+	unitsPerEm, err := u16(head, 18)
+	if err != nil {
+		return nil, err
+	}
+	if unitsPerEm == 0 {
+		return nil, fmt.Errorf("%v: zero units per em", errBadFont)
+	}
+	hhea, err := table(font, "hhea")
+	if err != nil {
+		return nil, err
+	}
+	numMetrics, err := u16(hhea, 34)
+	if err != nil {
+		return nil, err
+	}
+	if numMetrics == 0 {
+		return nil, fmt.Errorf("%v: no horizontal metrics", errBadFont)
+	}
+	hmtx, err := table(font, "hmtx")
+	if err != nil {
+		return nil, err
+	}
+	if 4*numMetrics > len(hmtx) {
+		return nil, fmt.Errorf("%v: %d metrics", errBadFont, numMetrics)
+	}
+	advance := func(glyph int) int {
+		if glyph >= numMetrics {
+			// Glyphs after last metric have its advance.
+			glyph = numMetrics - 1
+		}
+		v, _ := u16(hmtx, 4*glyph)
+		return v
+	}
+	m := &Metrics{
+		UnitsPerEm: unitsPerEm,
+		advances:   make(map[rune]int),
+		missing:    advance(0),
+	}
+	if err = readGlyphs(font, func(r rune, glyph int) {
+		m.advances[r] = advance(glyph)
+	}); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Width returns width in pixels of the widest line of s for font size
+// in pixels.
+func (m *Metrics) Width(s string, size float64) float64 {
+	var widest int
+	for _, line := range strings.Split(s, "\n") {
+		var width int
+		for _, r := range line {
+			advance, ok := m.advances[r]
+			if !ok {
+				advance = m.missing
+			}
+			width += advance
+		}
+		if width > widest {
+			widest = width
+		}
+	}
+	return float64(widest) * size / float64(m.UnitsPerEm)
+}
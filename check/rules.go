@@ -0,0 +1,387 @@
+package check
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Off is severity of disabled rule.
+const Off = "off"
+
+// Rule checks translated entry.
+type Rule struct {
+	Name        string
+	Description string
+	Severity    string // default
+	// Check returns message of issue, or blank string if entry is fine.
+	Check func(e resource.Entry) string
+}
+
+// Rules are built-in rules of Linter.
+var Rules = []Rule{
+	{
+		Name:        "placeholders",
+		Description: "Placeholders like {0} or {THING:ItemFlour} match original",
+		Severity:    Error,
+		Check:       checkPlaceholders,
+	},
+	{
+		Name:        "whitespace",
+		Description: "Leading and trailing whitespace match original",
+		Severity:    Warning,
+		Check:       checkWhitespace,
+	},
+	{
+		Name:        "double-space",
+		Description: "No doubled spaces",
+		Severity:    Warning,
+		Check:       checkDoubleSpace,
+	},
+	{
+		Name:        "untranslated",
+		Description: "No english text of original is left in translation",
+		Severity:    Warning,
+		Check:       untranslatedCheck(DefaultUntranslatedRatio),
+	},
+	{
+		Name:        "punctuation",
+		Description: "Punctuation at the end matches original",
+		Severity:    Warning,
+		Check:       checkPunctuation,
+	},
+	{
+		Name:        "nbsp",
+		Description: "No-break space between number and unit",
+		Severity:    Warning,
+		Check:       checkNBSP,
+	},
+	{
+		Name:        "tag-case",
+		Description: "Capitalisation of tags like {THING:ItemFlour} matches original",
+		Severity:    Error,
+		Check:       checkTagCase,
+	},
+}
+
+// RuleConfig overrides severity of rule, globally and for languages by
+// code. Use Off severity to disable rule.
+type RuleConfig struct {
+	Severity  string            `mapstructure:"severity"`
+	Languages map[string]string `mapstructure:"languages"` // code to severity
+	// Ratio overrides DefaultUntranslatedRatio of untranslated rule.
+	Ratio float64 `mapstructure:"ratio"`
+}
+
+// Linter checks entries with rules.
+type Linter struct {
+	rules  []Rule
+	config map[string]RuleConfig
+}
+
+func validSeverity(s string) bool {
+	switch s {
+	case "", Error, Warning, Off:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewLinter returns linter of built-in rules with config by rule name.
+func NewLinter(config map[string]RuleConfig) (*Linter, error) {
+	l := &Linter{
+		rules:  append([]Rule(nil), Rules...),
+		config: make(map[string]RuleConfig, len(config)),
+	}
+	for name, c := range config {
+		if _, ok := RuleByName(name); !ok {
+			return nil, fmt.Errorf("unknown rule %q", name)
+		}
+		if !validSeverity(c.Severity) {
+			return nil, fmt.Errorf("rule %s: bad severity %q", name, c.Severity)
+		}
+		switch {
+		case c.Ratio == 0:
+		case name != "untranslated":
+			return nil, fmt.Errorf("rule %s: ratio is not supported", name)
+		case c.Ratio < 0 || c.Ratio > 1:
+			return nil, fmt.Errorf("rule %s: bad ratio %v", name, c.Ratio)
+		default:
+			for i := range l.rules {
+				if l.rules[i].Name == name {
+					l.rules[i].Check = untranslatedCheck(c.Ratio)
+				}
+			}
+		}
+		languages := make(map[string]string, len(c.Languages))
+		for code, severity := range c.Languages {
+			if !validSeverity(severity) {
+				return nil, fmt.Errorf("rule %s: bad severity %q of %s", name, severity, code)
+			}
+			languages[strings.ToUpper(code)] = severity
+		}
+		c.Languages = languages
+		l.config[name] = c
+	}
+	return l, nil
+}
+
+// RuleByName returns built-in rule.
+func RuleByName(name string) (Rule, bool) {
+	for _, r := range Rules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return Rule{}, false
+}
+
+// Severity returns severity of rule for language.
+func (l *Linter) Severity(rule Rule, language string) string {
+	c := l.config[rule.Name]
+	if s := c.Languages[strings.ToUpper(language)]; s != "" {
+		return s
+	}
+	if c.Severity != "" {
+		return c.Severity
+	}
+	return rule.Severity
+}
+
+// Lint returns issues of translated entries of language.
+func (l *Linter) Lint(language string, entries resource.Entries) []Issue {
+	var issues []Issue
+	for _, r := range l.rules {
+		severity := l.Severity(r, language)
+		if severity == Off {
+			continue
+		}
+		for _, e := range entries {
+			if e.Str == "" || resource.IsSentinel(e.Str) || e.Original == resource.Blank || e.Fuzzy {
+				continue
+			}
+			msg := r.Check(e)
+			if msg == "" {
+				continue
+			}
+			issues = append(issues, Issue{
+				Rule:     r.Name,
+				Severity: severity,
+				Language: language,
+				File:     e.File,
+				Context:  e.Context,
+				ID:       e.ID,
+				Str:      e.Str,
+				Message:  msg,
+			})
+		}
+	}
+	return issues
+}
+
+// placeholder matches placeholders like {0}, {BLANK} or {KEY:Jetpack}.
+var placeholder = regexp.MustCompile(`{[^{}\s]+}`)
+
+func placeholders(s string) []string {
+	found := placeholder.FindAllString(s, -1)
+	sort.Strings(found)
+	return found
+}
+
+// checkPlaceholders compares placeholders ignoring case, that is
+// checked by checkTagCase.
+func checkPlaceholders(e resource.Entry) string {
+	var (
+		original = placeholders(e.Original)
+		str      = placeholders(e.Str)
+		count    = make(map[string]int)
+		missing  []string
+		extra    []string
+	)
+	for _, p := range original {
+		count[strings.ToLower(p)]++
+	}
+	for _, p := range str {
+		count[strings.ToLower(p)]--
+	}
+	for _, p := range append(original, str...) {
+		key := strings.ToLower(p)
+		switch n := count[key]; {
+		case n > 0:
+			missing = append(missing, p)
+		case n < 0:
+			extra = append(extra, p)
+		default:
+			continue
+		}
+		count[key] = 0
+	}
+	var parts []string
+	if len(missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(missing, ", "))
+	}
+	if len(extra) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(extra, ", "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func checkWhitespace(e resource.Entry) string {
+	var parts []string
+	leading := func(s string) string {
+		return s[:len(s)-len(strings.TrimLeftFunc(s, unicode.IsSpace))]
+	}
+	trailing := func(s string) string {
+		return s[len(strings.TrimRightFunc(s, unicode.IsSpace)):]
+	}
+	if leading(e.Original) != leading(e.Str) {
+		parts = append(parts, fmt.Sprintf("leading whitespace %q, original has %q", leading(e.Str), leading(e.Original)))
+	}
+	if trailing(e.Original) != trailing(e.Str) {
+		parts = append(parts, fmt.Sprintf("trailing whitespace %q, original has %q", trailing(e.Str), trailing(e.Original)))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func checkDoubleSpace(e resource.Entry) string {
+	trimmed := strings.TrimSpace(e.Str)
+	if strings.Contains(trimmed, "  ") && !strings.Contains(e.Original, "  ") {
+		return "doubled space"
+	}
+	return ""
+}
+
+// untranslatedWords is minimal count of consecutive original words in
+// translation that are reported.
+const untranslatedWords = 3
+
+// DefaultUntranslatedRatio is default minimal share of translation words
+// that are english text of original to report, see RuleConfig.Ratio.
+// Names and cognates, like in latin-script languages, are shorter.
+const DefaultUntranslatedRatio = 0.5
+
+// words returns lower-case words of letters, without placeholders and
+// rich text tags.
+func words(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(placeholder.ReplaceAllString(Visible(s), " ")), func(r rune) bool {
+		return !(unicode.IsLetter(r) || r == '\'')
+	})
+}
+
+// untranslatedCheck returns check of untranslated rule that reports
+// translations where runs of at least untranslatedWords original words
+// are at least ratio of all words.
+func untranslatedCheck(ratio float64) func(e resource.Entry) string {
+	return func(e resource.Entry) string {
+		if e.Str == e.Original {
+			// Identical translations are intentional, like names of keys.
+			return ""
+		}
+		var (
+			original, str = words(e.Original), words(e.Str)
+			english       = make([]bool, len(str))
+			first         []string
+		)
+		for i := 0; i+untranslatedWords <= len(str); i++ {
+			for j := 0; j+untranslatedWords <= len(original); j++ {
+				n := 0
+				for i+n < len(str) && j+n < len(original) && str[i+n] == original[j+n] {
+					n++
+				}
+				if n < untranslatedWords {
+					continue
+				}
+				if first == nil {
+					first = str[i : i+n]
+				}
+				for k := i; k < i+n; k++ {
+					english[k] = true
+				}
+			}
+		}
+		count := 0
+		for _, ok := range english {
+			if ok {
+				count++
+			}
+		}
+		if first == nil || float64(count) < ratio*float64(len(str)) {
+			return ""
+		}
+		return fmt.Sprintf("contains english text %q", strings.Join(first, " "))
+	}
+}
+
+// punctuation maps final punctuation to its kind.
+var punctuation = map[rune]rune{
+	'.': '.', '。': '.', '।': '.',
+	'!': '!', '！': '!',
+	'?': '?', '？': '?',
+	':': ':', '：': ':',
+	';': ';', '；': ';',
+	'…': '…',
+}
+
+// finalPunctuation returns kind of punctuation at the end of visible
+// text, or zero.
+func finalPunctuation(s string) rune {
+	s = strings.TrimRightFunc(Visible(s), unicode.IsSpace)
+	if strings.HasSuffix(s, "...") {
+		return '…'
+	}
+	runes := []rune(s)
+	if len(runes) == 0 {
+		return 0
+	}
+	return punctuation[runes[len(runes)-1]]
+}
+
+func checkPunctuation(e resource.Entry) string {
+	original, str := finalPunctuation(e.Original), finalPunctuation(e.Str)
+	switch {
+	case original == str:
+		return ""
+	case original == 0:
+		return fmt.Sprintf("ends with %q, original does not", str)
+	case str == 0:
+		return fmt.Sprintf("does not end with %q like original", original)
+	default:
+		return fmt.Sprintf("ends with %q, original ends with %q", str, original)
+	}
+}
+
+// unitSpace matches number and unit separated by regular space.
+var unitSpace = regexp.MustCompile(`\d( )(kPa|MPa|Pa|kW|W|kJ|J|K|°C|°K|L|mol|kg|g|km|m|s|%|кПа|МПа|Па|кВт|Вт|кДж|Дж|К|л|моль|кг|г|км|м|с)(?:[^\p{L}]|$)`)
+
+func checkNBSP(e resource.Entry) string {
+	var units []string
+	for _, m := range unitSpace.FindAllStringSubmatch(e.Str, -1) {
+		units = append(units, m[2])
+	}
+	if len(units) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("use no-break space before %s", strings.Join(units, ", "))
+}
+
+// tag matches tags like {THING:ItemFlour}.
+var tag = regexp.MustCompile(`{([A-Za-z]+):([^{}]+)}`)
+
+func checkTagCase(e resource.Entry) string {
+	original := make(map[string]string)
+	for _, t := range tag.FindAllString(e.Original, -1) {
+		original[strings.ToLower(t)] = t
+	}
+	var wrong []string
+	for _, t := range tag.FindAllString(e.Str, -1) {
+		if o, ok := original[strings.ToLower(t)]; ok && o != t {
+			wrong = append(wrong, fmt.Sprintf("%s should be %s", t, o))
+		}
+	}
+	return strings.Join(wrong, ", ")
+}
@@ -0,0 +1,168 @@
+package check
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+func TestRules(t *testing.T) {
+	for _, tt := range []struct {
+		Rule     string
+		Original string
+		Str      string
+		Message  string
+	}{
+		{"placeholders", "Press {KEY:Jetpack} for {0}s", "Нажмите {KEY:Jetpack} на {0} с", ""},
+		{"placeholders", "{0} of {1}", "{0} из {2}", "missing {1}; unexpected {2}"},
+		{"placeholders", "{THING:ItemFlour}", "{THING:itemflour}", ""},
+		{"whitespace", "Flour ", "Мука ", ""},
+		{"whitespace", " Flour", "Мука\n", `leading whitespace "", original has " "; trailing whitespace "\n", original has ""`},
+		{"double-space", "Milk  ", "Молоко  ", ""},
+		{"double-space", "Soy Milk", "Соевое  молоко", "doubled space"},
+		{"untranslated", "Open the door", "Open the door", ""},
+		{"untranslated", "Press to open the door now", "Нажмите to open the door", `contains english text "to open the door"`},
+		{"untranslated", "Open <b>the</b> door", "Открыть <b>the</b> дверь", ""},
+		{"untranslated", "{0}", "{0}", ""},
+		// Names and cognates in pt-BR.
+		{"untranslated", "Press {KEY:Use} to open the Auto Lathe Kit menu", "Pressione {KEY:Use} para abrir o menu do Auto Lathe Kit", ""},
+		{"untranslated", "Total pressure of gas mixture", "Pressão total da mistura de gás", ""},
+		{"untranslated", "Solar Panel", "Painel Solar", ""},
+		{"untranslated", "Turn on the gas mixer now", "Ligue the gas mixer now", `contains english text "the gas mixer now"`},
+		{"untranslated", "Open the door", "Abra a porta da frente, open the door", ""},
+		{"untranslated", "Open the door", "Abra open the door", `contains english text "open the door"`},
+		{"punctuation", "Done.", "Готово。", ""},
+		{"punctuation", "Wait...", "Ждите…", ""},
+		{"punctuation", "Done.", "Готово", `does not end with '.' like original`},
+		{"punctuation", "Done", "Готово!", `ends with '!', original does not`},
+		{"punctuation", "Done?", "Готово.", `ends with '.', original ends with '?'`},
+		{"nbsp", "{0} kPa", "{0} кПа", ""},
+		{"nbsp", "100 kPa at 20 °C", "100 кПа при 20 °C", "use no-break space before кПа, °C"},
+		{"nbsp", "100 kPa", "100 кПа, 5 минут", ""},
+		{"tag-case", "Use {THING:ItemFlour}", "Используйте {THING:ItemFlour}", ""},
+		{"tag-case", "Use {THING:ItemFlour}", "Используйте {Thing:itemFlour}", "{Thing:itemFlour} should be {THING:ItemFlour}"},
+	} {
+		r, ok := RuleByName(tt.Rule)
+		if !ok {
+			t.Fatalf("no rule %s", tt.Rule)
+		}
+		got := r.Check(resource.Entry{Original: tt.Original, Str: tt.Str})
+		if got != tt.Message {
+			t.Errorf("%s(%q, %q) = %q, expected %q", tt.Rule, tt.Original, tt.Str, got, tt.Message)
+		}
+	}
+}
+
+func TestLinter(t *testing.T) {
+	l, err := NewLinter(map[string]RuleConfig{
+		"untranslated": {Severity: Off, Languages: map[string]string{"ru": Error}},
+		"punctuation":  {Severity: Error},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	entries := resource.Entries{
+		{File: "Keys", Context: "Keys.Open", Original: "Open the door.", Str: "Open the door"},
+		{File: "Keys", Context: "Keys.Fuzzy", Original: "Close.", Str: "Закрыть", Fuzzy: true},
+		{File: "Keys", Context: "Keys.Blank", Original: "Blank.", Str: resource.Blank},
+	}
+	issues := l.Lint("RU", entries)
+	if len(issues) != 2 {
+		t.Fatalf("unexpected issues %+v", issues)
+	}
+	for _, i := range issues {
+		if i.Severity != Error || i.Language != "RU" || i.Context != "Keys.Open" {
+			t.Errorf("unexpected issue %+v", i)
+		}
+	}
+	if issues = l.Lint("DE", entries); len(issues) != 1 || issues[0].Rule != "punctuation" {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+	if _, err = NewLinter(map[string]RuleConfig{"spelling": {}}); err == nil {
+		t.Error("should fail on unknown rule")
+	}
+	if _, err = NewLinter(map[string]RuleConfig{"nbsp": {Severity: "fatal"}}); err == nil {
+		t.Error("should fail on bad severity")
+	}
+	for _, c := range []map[string]RuleConfig{
+		{"untranslated": {Ratio: 1.5}},
+		{"nbsp": {Ratio: 0.5}},
+	} {
+		if _, err = NewLinter(c); err == nil {
+			t.Errorf("should fail on bad ratio %+v", c)
+		}
+	}
+	if l, err = NewLinter(map[string]RuleConfig{"untranslated": {Ratio: 0.25}}); err != nil {
+		t.Fatal(err)
+	}
+	entries = resource.Entries{
+		{File: "Tips", Original: "Press {KEY:Use} to open the Auto Lathe Kit menu", Str: "Pressione {KEY:Use} para abrir o menu do Auto Lathe Kit"},
+	}
+	if issues = l.Lint("PB", entries); len(issues) != 1 || issues[0].Rule != "untranslated" {
+		t.Errorf("unexpected issues %+v", issues)
+	}
+	if r, _ := RuleByName("untranslated"); r.Check(entries[0]) != "" {
+		t.Error("built-in rule should not be changed by config")
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	issues := []Issue{
+		{Rule: "nbsp", Severity: Warning, Language: "RU", File: "Gases", Context: "Gases.Pressure", Message: "use no-break space before кПа"},
+		{Rule: "font", Severity: Error, Language: "RU", File: "Gases", Message: "font"},
+	}
+	buf := new(bytes.Buffer)
+	if err := WriteSARIF(buf, issues, func(i Issue) Location {
+		return Location{URI: "locales/ru/" + i.File + ".po", Line: len(i.Context)}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID string `json:"id"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Level     string `json:"level"`
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log %s", buf)
+	}
+	run := log.Runs[0]
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "font" {
+		t.Errorf("unexpected rules %+v", run.Tool.Driver.Rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("unexpected results %+v", run.Results)
+	}
+	nbsp := run.Results[0].Locations[0].PhysicalLocation
+	if nbsp.ArtifactLocation.URI != "locales/ru/Gases.po" || nbsp.Region == nil || nbsp.Region.StartLine != 14 {
+		t.Errorf("unexpected location %+v", nbsp)
+	}
+	if run.Results[1].Level != "error" || run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Errorf("unexpected result %+v", run.Results[1])
+	}
+}
@@ -0,0 +1,131 @@
+package check
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// Location is location of issue in file.
+type Location struct {
+	URI  string // slash-separated path relative to repository root
+	Line int    // starts from 1, zero if unknown
+}
+
+// descriptions of rules that are not in Rules.
+var descriptions = map[string]string{
+	"font":        "Characters are supported by font of language",
+	"length":      "Translation does not exceed length limit",
+	"consistency": "The same original text is translated the same way",
+	"ambiguity":   "Different original texts are not translated the same way",
+	"sentinels":   "Sentinels like {SAME} or {USE:Things.ItemFlour} are valid",
+	"language":    "Translation is written in language of translation",
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+	Region *sarifRegion `json:"region,omitempty"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver struct {
+			Name           string      `json:"name"`
+			InformationURI string      `json:"informationUri"`
+			Rules          []sarifRule `json:"rules"`
+		} `json:"driver"`
+	} `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// WriteSARIF writes issues as SARIF 2.1.0 log for code scanning
+// annotations, using locate to find files of issues.
+func WriteSARIF(w io.Writer, issues []Issue, locate func(Issue) Location) error {
+	var run sarifRun
+	run.Tool.Driver.Name = "martian"
+	run.Tool.Driver.InformationURI = "https://github.com/st-l10n/martian"
+	run.Tool.Driver.Rules = []sarifRule{}
+	run.Results = []sarifResult{}
+	seen := make(map[string]bool)
+	for _, i := range issues {
+		if !seen[i.Rule] {
+			seen[i.Rule] = true
+			description := descriptions[i.Rule]
+			if r, ok := RuleByName(i.Rule); ok {
+				description = r.Description
+			}
+			run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+				ID:               i.Rule,
+				ShortDescription: sarifMessage{Text: description},
+			})
+		}
+		loc := locate(i)
+		var l sarifLocation
+		l.PhysicalLocation.ArtifactLocation.URI = loc.URI
+		if loc.Line > 0 {
+			l.PhysicalLocation.Region = &sarifRegion{StartLine: loc.Line}
+		}
+		if i.Context != "" {
+			l.LogicalLocations = []sarifLogicalLocation{
+				{FullyQualifiedName: i.Language + "/" + i.Context},
+			}
+		}
+		level := i.Severity
+		if level != Error {
+			level = "warning"
+		}
+		run.Results = append(run.Results, sarifResult{
+			RuleID:    i.Rule,
+			Level:     level,
+			Message:   sarifMessage{Text: i.Message},
+			Locations: []sarifLocation{l},
+		})
+	}
+	sort.Slice(run.Tool.Driver.Rules, func(i, j int) bool {
+		return run.Tool.Driver.Rules[i].ID < run.Tool.Driver.Rules[j].ID
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	})
+}
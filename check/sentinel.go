@@ -0,0 +1,22 @@
+package check
+
+import "github.com/st-10n/martian/resource"
+
+// CheckSentinels returns issues for invalid sentinels in translations,
+// see resource.ValidateSentinels.
+func CheckSentinels(language string, entries resource.Entries) []Issue {
+	var issues []Issue
+	for _, err := range resource.ValidateSentinels(entries) {
+		issues = append(issues, Issue{
+			Rule:     "sentinels",
+			Severity: Error,
+			Language: language,
+			File:     err.Entry.File,
+			Context:  err.Entry.Context,
+			ID:       err.Entry.ID,
+			Str:      err.Entry.Str,
+			Message:  err.Message,
+		})
+	}
+	return issues
+}
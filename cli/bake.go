@@ -9,30 +9,13 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/st-10n/martian/check"
 	"github.com/st-10n/martian/resource"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-func stringIn(s string, list []string) bool {
-	for _, l := range list {
-		if l == s {
-			return true
-		}
-	}
-	return false
-}
-
-type originalFile struct {
-	Postfix string
-	Path    string
-}
-
-func (o originalFile) String() string {
-	return path.Join(o.Path, "english"+o.Postfix)
-}
-
 var bakeCmd = &cobra.Command{
 	Use: "bake",
 	Aliases: []string{
@@ -44,13 +27,19 @@ var bakeCmd = &cobra.Command{
 			f = cmd.Flags()
 
 			outDir, inDir string
-			templates     []originalFile
+			templates     []resource.Template
 			limit         []string
 			ignore        []string
 			err           error
 			languages     Languages
 			assetsName    string
 			english       Language
+			provenance    bool
+			comment       string
+			checks        []string
+			checkLanguage checker
+			validate      bool
+			schemas       = make(map[resource.Template]*resource.Schema)
 		)
 		if inDir, err = f.GetString("input"); err != nil {
 			return err
@@ -93,7 +82,7 @@ var bakeCmd = &cobra.Command{
 		if err = filepath.Walk(outDir, func(path string, info os.FileInfo, err error) error {
 			if info.IsDir() {
 				for _, i := range ignore {
-					if stringIn(path, []string{i, filepath.Join(outDir, i)}) {
+					if resource.StringIn(path, []string{i, filepath.Join(outDir, i)}) {
 						fmt.Println("skipping the", path)
 						return filepath.SkipDir
 					}
@@ -105,9 +94,9 @@ var bakeCmd = &cobra.Command{
 				return err
 			}
 			if strings.HasPrefix(base, "english") && strings.HasSuffix(base, ".xml") {
-				templates = append(templates, originalFile{
+				templates = append(templates, resource.Template{
 					Postfix: strings.TrimPrefix(base, "english"),
-					Path:    relative,
+					Path:    filepath.ToSlash(relative),
 				})
 			}
 			return nil
@@ -117,6 +106,37 @@ var bakeCmd = &cobra.Command{
 		if len(templates) == 0 {
 			return errors.New("no english files found in output folder")
 		}
+		if provenance, err = f.GetBool("provenance"); err != nil {
+			return err
+		}
+		if provenance {
+			comment = "Generated by martian"
+			if data, readErr := readFile(filepath.Join(outDir, "version.txt")); readErr == nil {
+				comment += " for v" + strings.TrimSpace(string(data))
+			}
+			hash, hashErr := headHash(inDir)
+			if hashErr != nil {
+				fmt.Println("warning: no locale commit:", hashErr)
+			} else {
+				comment += " from locales commit " + hash.String()
+			}
+		}
+		// Parts with english fallback by language code, lowercased by viper.
+		var fallback map[string][]string
+		if err = viper.UnmarshalKey("fallback", &fallback); err != nil {
+			return err
+		}
+		if validate, err = f.GetBool("validate"); err != nil {
+			return err
+		}
+		if checks, err = f.GetStringSlice("check"); err != nil {
+			return err
+		}
+		if len(checks) > 0 {
+			if checkLanguage, err = getChecker(checks); err != nil {
+				return err
+			}
+		}
 		simplified := viper.GetStringSlice("simplified")
 		fmt.Println("templates:", templates)
 		fmt.Println("limit:", limit)
@@ -176,6 +196,22 @@ var bakeCmd = &cobra.Command{
 				fmt.Println("skipping english as readonly")
 				continue
 			}
+			if checkLanguage != nil {
+				entries, err := readCatalogs(localeDir, "")
+				if err != nil {
+					return err
+				}
+				issues := checkLanguage(lang, entries)
+				check.Sort(issues)
+				if err = check.WriteText(os.Stdout, issues); err != nil {
+					return err
+				}
+				for _, i := range issues {
+					if i.Severity == check.Error {
+						return fmt.Errorf("checks of %s failed", lang.Name)
+					}
+				}
+			}
 			for _, t := range templates {
 				name := lang.Prefix + t.Postfix
 				outName := filepath.Join(outDir, t.Path, name)
@@ -190,11 +226,13 @@ var bakeCmd = &cobra.Command{
 					Name:        lang.Name,
 					Original:    orig,
 					Translation: localizations,
+					Comment:     comment,
+					Fallback:    fallback[strings.ToLower(lang.Code)],
 				}
 				if lang.Font != "" {
 					opt.Font = "font_" + lang.Font
 				}
-				if !stringIn(t.Postfix, []string{".xml", "_tutorial.xml", "_mars_mission.xml"}) {
+				if !resource.StringIn(t.Postfix, []string{".xml", "_tutorial.xml", "_mars_mission.xml"}) {
 					opt.Font = ""
 					opt.Name = ""
 				}
@@ -202,6 +240,18 @@ var bakeCmd = &cobra.Command{
 				if err != nil {
 					return err
 				}
+				if validate {
+					schema, ok := schemas[t]
+					if !ok {
+						if schema, err = resource.InferSchema(orig); err != nil {
+							return fmt.Errorf("failed to infer schema of %s: %v", origName, err)
+						}
+						schemas[t] = schema
+					}
+					if printValidationErrors(outName, resource.Validate(out, schema)) > 0 {
+						return fmt.Errorf("baked %s is invalid", outName)
+					}
+				}
 				outF, err := os.Create(outName)
 				if err != nil {
 					return err
@@ -228,6 +278,9 @@ func init() {
 		f.StringP("input", "i", "locales", "input directory (locales)")
 		f.StringSlice("limit", nil, "limit languages")
 		f.StringSlice("ignore", []string{"game"}, "ignore directories")
+		f.Bool("provenance", true, "add comment with game version and locale commit")
+		f.Bool("validate", true, "validate structure of baked files")
+		f.StringSlice("check", nil, "checks of translations before baking (font, length, lint, sentinels, language)")
 	}
 	rootCmd.AddCommand(
 		bakeCmd,
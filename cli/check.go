@@ -0,0 +1,428 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/st-10n/martian/assets"
+	"github.com/st-10n/martian/check"
+	"github.com/st-10n/martian/resource"
+)
+
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Validate translations",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+// poLocator returns function that finds issues in catalogs of inDir,
+// see readCatalogs. URIs of locations are relative to root directory if
+// it is not blank, like repository root for code scanning.
+func poLocator(inDir, root, prefix string, languages Languages) func(check.Issue) check.Location {
+	locales := make(map[string]string, len(languages))
+	for _, lang := range languages {
+		locales[lang.Code] = lang.GetLocale()
+	}
+	files := make(map[string][]string)
+	return func(i check.Issue) check.Location {
+		name := filepath.Join(inDir, locales[i.Language], prefix+i.File+".po")
+		lines, ok := files[name]
+		if !ok {
+			if data, err := readFile(name); err == nil {
+				lines = strings.Split(string(data), "\n")
+			}
+			files[name] = lines
+		}
+		uri := name
+		if root != "" {
+			if abs, err := filepath.Abs(name); err == nil {
+				if rel, err := filepath.Rel(root, abs); err == nil {
+					uri = rel
+				}
+			}
+		}
+		loc := check.Location{
+			URI: filepath.ToSlash(uri),
+		}
+		ctx := "msgctxt " + strconv.Quote(i.Context)
+		for n, line := range lines {
+			if i.Context != "" && line == ctx {
+				loc.Line = n + 1
+				break
+			}
+		}
+		return loc
+	}
+}
+
+// writeIssues writes issues in text, json or sarif format to output and
+// returns error if there are any issues with error severity.
+func writeIssues(outName, format string, issues []check.Issue, locate func(check.Issue) check.Location) error {
+	check.Sort(issues)
+	out, err := createOutput(outName)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	switch format {
+	case "text":
+		err = check.WriteText(out, issues)
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if issues == nil {
+			issues = []check.Issue{}
+		}
+		err = enc.Encode(issues)
+	case "sarif":
+		err = check.WriteSARIF(out, issues, locate)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	var errCount int
+	for _, i := range issues {
+		if i.Severity == check.Error {
+			errCount++
+		}
+	}
+	if errCount > 0 {
+		return fmt.Errorf("%d errors found", errCount)
+	}
+	return nil
+}
+
+// fontConfig describes characters of font.
+type fontConfig struct {
+	Ranges []string `mapstructure:"ranges"` // like "0400-04FF"
+	Chars  string   `mapstructure:"chars"`  // additional characters
+	File   string   `mapstructure:"file"`   // TrueType or OpenType font
+}
+
+// getFonts returns charsets of fonts from "fonts" config and metrics of
+// fonts with file.
+func getFonts() (check.Fonts, map[string]*check.Metrics, error) {
+	var configs map[string]fontConfig
+	if err := viper.UnmarshalKey("fonts", &configs); err != nil {
+		return nil, nil, err
+	}
+	fonts := make(check.Fonts, len(configs))
+	metrics := make(map[string]*check.Metrics)
+	for name, c := range configs {
+		charset, err := check.ParseRanges(c.Ranges)
+		if err != nil {
+			return nil, nil, fmt.Errorf("font %s: %v", name, err)
+		}
+		charset = charset.Add(check.CharsetOf(c.Chars)...)
+		if c.File != "" {
+			data, err := readFile(c.File)
+			if err != nil {
+				return nil, nil, err
+			}
+			cmap, err := check.ReadCmap(data)
+			if err != nil {
+				return nil, nil, fmt.Errorf("font %s: %s: %v", name, c.File, err)
+			}
+			charset = charset.Add(cmap...)
+			if metrics[name], err = check.ReadMetrics(data); err != nil {
+				return nil, nil, fmt.Errorf("font %s: %s: %v", name, c.File, err)
+			}
+		}
+		fonts[name] = charset
+	}
+	return fonts, metrics, nil
+}
+
+// languageFont returns name of font of language in baked files.
+func languageFont(lang Language) string {
+	if lang.Font == "" {
+		// Font of original file is kept by bake.
+		return "english"
+	}
+	return lang.Font
+}
+
+// checker returns issues of language entries.
+type checker func(lang Language, entries resource.Entries) []check.Issue
+
+// fontChecker returns checker of characters supported by fonts, see
+// check.CheckFont.
+func fontChecker() (checker, error) {
+	fonts, _, err := getFonts()
+	if err != nil {
+		return nil, err
+	}
+	if len(fonts) == 0 {
+		return nil, errors.New("no fonts configured")
+	}
+	return func(lang Language, entries resource.Entries) []check.Issue {
+		font := languageFont(lang)
+		if _, ok := fonts[font]; !ok {
+			fmt.Fprintf(os.Stderr, "warning: no charset of font %s (%s)\n", font, lang.Name)
+			return nil
+		}
+		return check.CheckFont(lang.Code, font, fonts, entries)
+	}, nil
+}
+
+// lengthChecker returns checker of "limits", see check.CheckLength.
+func lengthChecker() (checker, error) {
+	var limits []check.Limit
+	if err := viper.UnmarshalKey("limits", &limits); err != nil {
+		return nil, err
+	}
+	if len(limits) == 0 {
+		return nil, errors.New("no limits configured")
+	}
+	_, metrics, err := getFonts()
+	if err != nil {
+		return nil, err
+	}
+	return func(lang Language, entries resource.Entries) []check.Issue {
+		return check.CheckLength(lang.Code, limits, metrics[languageFont(lang)], entries)
+	}, nil
+}
+
+// lintChecker returns checker of built-in rules configured in "rules",
+// see check.Linter.
+func lintChecker() (checker, error) {
+	var config map[string]check.RuleConfig
+	if err := viper.UnmarshalKey("rules", &config); err != nil {
+		return nil, err
+	}
+	l, err := check.NewLinter(config)
+	if err != nil {
+		return nil, err
+	}
+	return func(lang Language, entries resource.Entries) []check.Issue {
+		return l.Lint(lang.Code, entries)
+	}, nil
+}
+
+// ambiguityChecker returns checker of translations shared by different
+// msgids, allowed by "ambiguity.allow", see check.CheckAmbiguity.
+func ambiguityChecker() (checker, error) {
+	var allow []check.Allow
+	if err := viper.UnmarshalKey("ambiguity.allow", &allow); err != nil {
+		return nil, err
+	}
+	return func(lang Language, entries resource.Entries) []check.Issue {
+		return check.CheckAmbiguity(lang.Code, allow, entries)
+	}, nil
+}
+
+// trainIdentifier returns identifier with profiles of languages, trained
+// on their game files in game directory or archive.
+func trainIdentifier(game string, languages Languages) (*check.Identifier, error) {
+	input, inputCloser, err := assets.Open(game)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", game, err)
+	}
+	defer inputCloser.Close()
+	templates, err := resource.FindTemplates(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %v", game, err)
+	}
+	if len(templates) == 0 {
+		return nil, fmt.Errorf("no english files found in %s", game)
+	}
+	id := check.NewIdentifier()
+	for _, lang := range languages {
+		var texts []string
+		for _, t := range templates {
+			entries, err := t.Gen(input, lang.GetPrefix(), resource.GenOptions{
+				Simplified: viper.GetStringSlice("simplified"),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to gen %s: %v", t, err)
+			}
+			for _, e := range entries {
+				switch {
+				case lang.IsEnglish():
+					texts = append(texts, e.Original)
+				case e.Str != "" && e.Str != e.Original && !resource.IsSentinel(e.Str):
+					// Identical texts are english ones left in game files.
+					texts = append(texts, e.Str)
+				}
+			}
+		}
+		if len(texts) == 0 {
+			fmt.Fprintf(os.Stderr, "warning: no game texts of %s\n", lang.Name)
+			continue
+		}
+		id.Train(lang.Code, texts...)
+	}
+	return id, nil
+}
+
+// languageChecker returns checker of language of translations, identified
+// by profiles trained on game files of "langid.game", see
+// check.CheckLanguage.
+func languageChecker() (checker, error) {
+	languages, err := selectLanguages(nil)
+	if err != nil {
+		return nil, err
+	}
+	id, err := trainIdentifier(viper.GetString("langid.game"), languages)
+	if err != nil {
+		return nil, err
+	}
+	threshold := viper.GetFloat64("langid.threshold")
+	return func(lang Language, entries resource.Entries) []check.Issue {
+		if !id.Knows(lang.Code) {
+			fmt.Fprintf(os.Stderr, "warning: no language profile of %s\n", lang.Name)
+			return nil
+		}
+		return check.CheckLanguage(lang.Code, id, threshold, entries)
+	}, nil
+}
+
+// checkers are constructors of checkers by rule.
+var checkers = map[string]func() (checker, error){
+	"font":   fontChecker,
+	"length": lengthChecker,
+	"lint":   lintChecker,
+	"consistency": func() (checker, error) {
+		return func(lang Language, entries resource.Entries) []check.Issue {
+			return check.CheckConsistency(lang.Code, entries)
+		}, nil
+	},
+	"ambiguity": ambiguityChecker,
+	"sentinels": func() (checker, error) {
+		return func(lang Language, entries resource.Entries) []check.Issue {
+			return check.CheckSentinels(lang.Code, entries)
+		}, nil
+	},
+	"language": languageChecker,
+}
+
+// getChecker returns checker of rules.
+func getChecker(rules []string) (checker, error) {
+	var list []checker
+	for _, rule := range rules {
+		newChecker, ok := checkers[rule]
+		if !ok {
+			return nil, fmt.Errorf("unknown check %q", rule)
+		}
+		c, err := newChecker()
+		if err != nil {
+			return nil, fmt.Errorf("check %s: %v", rule, err)
+		}
+		list = append(list, c)
+	}
+	return func(lang Language, entries resource.Entries) []check.Issue {
+		var issues []check.Issue
+		for _, c := range list {
+			issues = append(issues, c(lang, entries)...)
+		}
+		return issues
+	}, nil
+}
+
+// newCheckCmd returns check subcommand of rule.
+func newCheckCmd(rule, short string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   rule,
+		Short: short,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				f = cmd.Flags()
+
+				inDir, outName, format string
+				prefix, root           string
+				limit                  []string
+				err                    error
+				languages              Languages
+				issues                 []check.Issue
+			)
+			if inDir, err = f.GetString("input"); err != nil {
+				return err
+			}
+			if outName, err = f.GetString("output"); err != nil {
+				return err
+			}
+			if format, err = f.GetString("format"); err != nil {
+				return err
+			}
+			if prefix, err = f.GetString("prefix"); err != nil {
+				return err
+			}
+			if root, err = f.GetString("root"); err != nil {
+				return err
+			}
+			if root == "" {
+				if root, err = worktreeRoot(inDir); err != nil {
+					return err
+				}
+			} else if root, err = filepath.Abs(root); err != nil {
+				return err
+			}
+			if limit, err = f.GetStringSlice("limit"); err != nil {
+				return err
+			}
+			if languages, err = selectLanguages(limit); err != nil {
+				return err
+			}
+			c, err := getChecker([]string{rule})
+			if err != nil {
+				return err
+			}
+			for _, lang := range languages {
+				if lang.IsEnglish() {
+					continue
+				}
+				localeDir := filepath.Join(inDir, lang.GetLocale())
+				if _, statErr := os.Stat(localeDir); os.IsNotExist(statErr) {
+					continue
+				}
+				entries, err := readCatalogs(localeDir, prefix)
+				if err != nil {
+					return err
+				}
+				issues = append(issues, c(lang, entries)...)
+			}
+			// Found issues are not usage errors.
+			cmd.SilenceUsage = true
+			return writeIssues(outName, format, issues, poLocator(inDir, root, prefix, languages))
+		},
+	}
+	f := cmd.Flags()
+	f.StringP("input", "i", "locales", "input directory (locales)")
+	f.StringP("output", "o", "-", "output file")
+	f.StringP("format", "f", "text", "output format (text, json, sarif)")
+	f.StringSlice("limit", nil, "limit languages")
+	f.StringP("prefix", "p", "", "filename prefix")
+	f.String("root", "", "root directory of sarif locations (git worktree of input by default)")
+	return cmd
+}
+
+func init() {
+	checkCmd.AddCommand(
+		newCheckCmd("font", "Check that fonts of languages contain all translated characters"),
+		newCheckCmd("length", "Check that translations do not exceed length limits"),
+		newCheckCmd("lint", "Check translations with built-in rules"),
+		newCheckConsistencyCmd(),
+		newCheckCmd("ambiguity", "Check that different english texts are not translated the same way"),
+		newCheckCmd("sentinels", "Check sentinels like {SAME} in translations"),
+		newCheckCmd("language", "Check that translations are not in english or other language"),
+	)
+	viper.SetDefault("langid.game", "game")
+	viper.SetDefault("langid.threshold", 0.9)
+	rootCmd.AddCommand(
+		checkCmd,
+	)
+}
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/st-10n/martian/vcs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport/http"
+)
+
+// commitMessage returns structured commit message with per-language
+// summary of staged files of repository in dir.
+func commitMessage(subject, dir string, staged []string, languages Languages) (string, error) {
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "%s: %s\n", vcs.Prefix, subject)
+	var (
+		byLang = make(map[Language][]string)
+		other  int
+	)
+	hasEnglish := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		return err == nil
+	}
+	for _, name := range staged {
+		found := false
+		for _, l := range languages {
+			if strings.SplitN(name, "/", 2)[0] == l.GetLocale() || isLanguageFile(name, l, hasEnglish) {
+				byLang[l] = append(byLang[l], name)
+				found = true
+				break
+			}
+		}
+		if !found {
+			other++
+		}
+	}
+	if len(byLang) > 0 {
+		b.WriteString("\n")
+	}
+	for _, l := range languages {
+		files := byLang[l]
+		if len(files) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "%s (%s): %d files", l.Name, l.GetLocale(), len(files))
+		localeDir := filepath.Join(dir, l.GetLocale())
+		if stat, err := os.Stat(localeDir); err == nil && stat.IsDir() {
+			entries, err := readCatalogs(localeDir, "")
+			if err != nil {
+				return "", err
+			}
+			s := entries.Stats()
+			fmt.Fprintf(b, ", %d/%d translated (%.1f%%)", s.Translated, s.Total, s.Progress())
+			if s.Fuzzy > 0 {
+				fmt.Fprintf(b, ", %d fuzzy", s.Fuzzy)
+			}
+		}
+		b.WriteString("\n")
+	}
+	if other > 0 {
+		fmt.Fprintf(b, "Other: %d files\n", other)
+	}
+	return b.String(), nil
+}
+
+var commitCmd = &cobra.Command{
+	Use:   "commit",
+	Short: "Commit generated files",
+	Long: `Commit generated files that match include patterns with structured
+message. Nothing is committed if there are no changes.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			f = cmd.Flags()
+
+			dir, subject      string
+			authorName, email string
+			remote            string
+			include           []string
+			push              bool
+			err               error
+			languages         Languages
+		)
+		if dir, err = f.GetString("dir"); err != nil {
+			return err
+		}
+		if subject, err = f.GetString("message"); err != nil {
+			return err
+		}
+		if include, err = f.GetStringSlice("include"); err != nil {
+			return err
+		}
+		if push, err = f.GetBool("push"); err != nil {
+			return err
+		}
+		if remote, err = f.GetString("remote"); err != nil {
+			return err
+		}
+		if len(include) == 0 {
+			return errors.New("no include patterns")
+		}
+		if err = viper.UnmarshalKey("languages", &languages); err != nil {
+			return err
+		}
+		authorName = viper.GetString("commit.name")
+		email = viper.GetString("commit.email")
+		repo, err := git.PlainOpen(dir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", dir, err)
+		}
+		staged, err := vcs.Stage(repo, include)
+		if err != nil {
+			return fmt.Errorf("failed to stage: %v", err)
+		}
+		for _, name := range staged {
+			fmt.Println("staged:", name)
+		}
+		message, err := commitMessage(subject, dir, staged, languages)
+		if err != nil {
+			return err
+		}
+		hash, err := vcs.Commit(repo, message, authorName, email)
+		if err != nil {
+			return fmt.Errorf("failed to commit: %v", err)
+		}
+		if hash.IsZero() {
+			fmt.Println("nothing to commit")
+		} else {
+			fmt.Println("commit:", hash)
+			fmt.Print(message)
+		}
+		if !push {
+			return nil
+		}
+		var auth transport.AuthMethod
+		if token := viper.GetString("git.token"); token != "" {
+			auth = &http.BasicAuth{
+				Username: "martian",
+				Password: token,
+			}
+		}
+		if err = vcs.Push(repo, remote, auth); err != nil {
+			return fmt.Errorf("failed to push to %s: %v", remote, err)
+		}
+		fmt.Println("pushed to", remote)
+		return nil
+	},
+}
+
+func init() {
+	{
+		f := commitCmd.Flags()
+		f.StringP("dir", "d", "locales", "repository directory")
+		f.StringP("message", "m", "update translations", "commit subject (prefixed with \""+vcs.Prefix+": \")")
+		f.StringSlice("include", []string{"*/*.po", "*/*.pot"}, "patterns of files to commit, relative to repository")
+		f.Bool("push", false, "push to remote after commit")
+		f.String("remote", "origin", "remote to push")
+		f.String("author", "Martian", "commit author name")
+		f.String("email", "martian@localhost", "commit author email")
+
+		viper.BindPFlag("commit.name", f.Lookup("author"))
+		viper.BindPFlag("commit.email", f.Lookup("email"))
+	}
+	rootCmd.AddCommand(
+		commitCmd,
+	)
+	viper.BindEnv("git.token", "GIT_TOKEN")
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/st-10n/martian/check"
+	"github.com/st-10n/martian/resource"
+)
+
+// propagate sets translation of inconsistent groups to the majority one,
+// or to str for group with id, as fuzzy in catalogs of localeDir.
+func propagate(localeDir, prefix, id, str string) error {
+	entries, err := readCatalogs(localeDir, prefix)
+	if err != nil {
+		return err
+	}
+	byFile := make(map[string][]resource.Translation)
+	for _, g := range check.Inconsistent(entries) {
+		if id != "" && g.ID != id {
+			continue
+		}
+		use := str
+		if use == "" {
+			var ok bool
+			if use, ok = g.Majority(); !ok {
+				fmt.Printf("  skipping %q: no majority\n", g.ID)
+				continue
+			}
+		}
+		for _, e := range g.Propagate(use) {
+			byFile[e.File] = append(byFile[e.File], resource.Translation{
+				Context: e.Context,
+				ID:      e.ID,
+				Str:     e.Str,
+				Fuzzy:   e.Fuzzy,
+			})
+		}
+	}
+	var files []string
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		translations := byFile[file]
+		name := filepath.Join(localeDir, prefix+file+".po")
+		data, err := readFile(name)
+		if err != nil {
+			return err
+		}
+		updated, changed, err := resource.SetTranslations(data, translations)
+		if err != nil {
+			return fmt.Errorf("failed to update %s: %v", name, err)
+		}
+		if err = writeFile(name, updated); err != nil {
+			return err
+		}
+		fmt.Printf("  %s: %d entries\n", name, changed)
+	}
+	return nil
+}
+
+// newCheckConsistencyCmd returns check subcommand of consistency, that
+// also propagates translations.
+func newCheckConsistencyCmd() *cobra.Command {
+	cmd := newCheckCmd("consistency", "Check that the same english text is translated the same way")
+	report := cmd.RunE
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		var (
+			f = cmd.Flags()
+
+			inDir, prefix string
+			id, str       string
+			limit         []string
+			doPropagate   bool
+			err           error
+			languages     Languages
+		)
+		if doPropagate, err = f.GetBool("propagate"); err != nil {
+			return err
+		}
+		if !doPropagate {
+			return report(cmd, args)
+		}
+		if inDir, err = f.GetString("input"); err != nil {
+			return err
+		}
+		if prefix, err = f.GetString("prefix"); err != nil {
+			return err
+		}
+		if id, err = f.GetString("id"); err != nil {
+			return err
+		}
+		if str, err = f.GetString("use"); err != nil {
+			return err
+		}
+		if str != "" && id == "" {
+			return errors.New("--use requires --id")
+		}
+		if limit, err = f.GetStringSlice("limit"); err != nil {
+			return err
+		}
+		if languages, err = selectLanguages(limit); err != nil {
+			return err
+		}
+		for _, lang := range languages {
+			if lang.IsEnglish() {
+				continue
+			}
+			localeDir := filepath.Join(inDir, lang.GetLocale())
+			if _, statErr := os.Stat(localeDir); os.IsNotExist(statErr) {
+				continue
+			}
+			fmt.Println("Language:", lang.Name)
+			if err = propagate(localeDir, prefix, id, str); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	f := cmd.Flags()
+	f.Bool("propagate", false, "set the majority translation as fuzzy to other entries")
+	f.String("id", "", "propagate only translation of msgid")
+	f.String("use", "", "propagate this translation of --id instead of the majority one")
+	return cmd
+}
@@ -0,0 +1,324 @@
+package cli
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/st-10n/martian/resource"
+	"github.com/st-10n/martian/vcs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// contributor is author of translations.
+type contributor struct {
+	Name    string `json:"name" xml:"Name,attr"`
+	Strings int    `json:"strings" xml:"Strings,attr"` // count of changed msgstr
+	Commits int    `json:"commits" xml:"Commits,attr"`
+}
+
+type languageContributors struct {
+	XMLName      xml.Name      `json:"-" xml:"Language"`
+	Code         string        `json:"code" xml:"Code,attr"`
+	Name         string        `json:"name" xml:"Name,attr"`
+	Locale       string        `json:"locale" xml:"-"`
+	Contributors []contributor `json:"contributors" xml:"Contributor"`
+}
+
+// catalogKey identifies po entry.
+type catalogKey struct {
+	Context, ID string
+}
+
+// catalogStrings returns translations of po file in commit by entry.
+func catalogStrings(c *object.Commit, name string) (map[catalogKey]string, error) {
+	if c == nil {
+		return nil, nil
+	}
+	data, err := readCommitFile(c, name)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	entries, err := resource.ReadCatalog(name, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	strs := make(map[catalogKey]string, len(entries))
+	for _, e := range entries {
+		if e.Str != "" && !e.Fuzzy {
+			strs[catalogKey{Context: e.Context, ID: e.ID}] = e.Str
+		}
+	}
+	return strs, nil
+}
+
+// changedStrings returns count of translations of po file that are added
+// or changed by commit.
+func changedStrings(c, parent *object.Commit, name string) (int, error) {
+	newStrs, err := catalogStrings(c, name)
+	if err != nil {
+		return 0, err
+	}
+	oldStrs, err := catalogStrings(parent, name)
+	if err != nil {
+		return 0, err
+	}
+	var count int
+	for k, s := range newStrs {
+		if oldStrs[k] != s {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// commitChange is count of changed translations of commit by locale
+// directory.
+type commitChange struct {
+	Author  object.Signature
+	Strings map[string]int
+}
+
+// attribute returns contributors by locale directory for changes of
+// commits, newest first, sorted by count of strings.
+//
+// Authors are identified by email, so contributors that renamed
+// themselves are listed once with the newest name.
+func attribute(changes []commitChange) map[string][]contributor {
+	var (
+		names  = make(map[string]string) // by email
+		counts = make(map[string]map[string]*contributor)
+	)
+	for _, ch := range changes {
+		id := strings.ToLower(ch.Author.Email)
+		if id == "" {
+			id = ch.Author.Name
+		}
+		if _, ok := names[id]; !ok {
+			names[id] = ch.Author.Name
+		}
+		for locale, count := range ch.Strings {
+			if count == 0 {
+				continue
+			}
+			if counts[locale] == nil {
+				counts[locale] = make(map[string]*contributor)
+			}
+			c := counts[locale][id]
+			if c == nil {
+				c = &contributor{}
+				counts[locale][id] = c
+			}
+			c.Strings += count
+			c.Commits++
+		}
+	}
+	result := make(map[string][]contributor, len(counts))
+	for locale, byID := range counts {
+		list := make([]contributor, 0, len(byID))
+		for id, c := range byID {
+			c.Name = names[id]
+			list = append(list, *c)
+		}
+		sort.Slice(list, func(i, j int) bool {
+			a, b := list[i], list[j]
+			if a.Strings != b.Strings {
+				return a.Strings > b.Strings
+			}
+			return a.Name < b.Name
+		})
+		result[locale] = list
+	}
+	return result
+}
+
+// contributions returns contributors by locale directory for
+// non-automated commits of repository history, see attribute.
+//
+// Merge commits are skipped, so changes are attributed to original authors.
+func contributions(repo *git.Repository) (map[string][]contributor, error) {
+	ref, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	iter, err := repo.Log(&git.LogOptions{
+		From: ref.Hash(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	var changes []commitChange
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.NumParents() > 1 || strings.HasPrefix(c.Message, vcs.Prefix) {
+			return nil
+		}
+		var parent *object.Commit
+		if c.NumParents() == 1 {
+			if parent, err = c.Parent(0); err != nil {
+				return err
+			}
+		}
+		names, err := changedFiles(c)
+		if err != nil {
+			return err
+		}
+		ch := commitChange{
+			Author:  c.Author,
+			Strings: make(map[string]int),
+		}
+		for _, name := range names {
+			if !strings.HasSuffix(name, ".po") || path.Dir(name) == "." {
+				continue
+			}
+			count, err := changedStrings(c, parent, name)
+			if err != nil {
+				return err
+			}
+			ch.Strings[strings.SplitN(name, "/", 2)[0]] += count
+		}
+		changes = append(changes, ch)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return attribute(changes), nil
+}
+
+func writeContributorsText(w io.Writer, langs []languageContributors) error {
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(t, "Language\tContributor\tStrings\tCommits")
+	for _, l := range langs {
+		for _, c := range l.Contributors {
+			fmt.Fprintf(t, "%s\t%s\t%d\t%d\n", l.Name, c.Name, c.Strings, c.Commits)
+		}
+	}
+	return t.Flush()
+}
+
+// writeCredits writes plain text credits, language per line.
+func writeCredits(w io.Writer, langs []languageContributors) error {
+	for _, l := range langs {
+		var names []string
+		for _, c := range l.Contributors {
+			names = append(names, c.Name)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", l.Name, strings.Join(names, ", ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCreditsXML(w io.Writer, langs []languageContributors) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(struct {
+		XMLName   xml.Name `xml:"Credits"`
+		Languages []languageContributors
+	}{
+		Languages: langs,
+	}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+var contributorsCmd = &cobra.Command{
+	Use:   "contributors",
+	Short: "List translators from locales repository history",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			f = cmd.Flags()
+
+			inDir, outName, format string
+			limit                  []string
+			err                    error
+			languages              Languages
+			langs                  []languageContributors
+		)
+		if inDir, err = f.GetString("input"); err != nil {
+			return err
+		}
+		if outName, err = f.GetString("output"); err != nil {
+			return err
+		}
+		if format, err = f.GetString("format"); err != nil {
+			return err
+		}
+		if limit, err = f.GetStringSlice("limit"); err != nil {
+			return err
+		}
+		if languages, err = selectLanguages(limit); err != nil {
+			return err
+		}
+		repo, err := git.PlainOpen(inDir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", inDir, err)
+		}
+		byLocale, err := contributions(repo)
+		if err != nil {
+			return fmt.Errorf("failed to read history: %v", err)
+		}
+		for _, lang := range languages {
+			authors := byLocale[lang.GetLocale()]
+			if lang.IsEnglish() || len(authors) == 0 {
+				continue
+			}
+			l := languageContributors{
+				Code:   lang.Code,
+				Name:   lang.Name,
+				Locale: lang.GetLocale(),
+			}
+			l.Contributors = authors
+			langs = append(langs, l)
+		}
+		out, err := createOutput(outName)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		switch format {
+		case "text":
+			err = writeContributorsText(out, langs)
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(langs)
+		case "credits":
+			err = writeCredits(out, langs)
+		case "xml":
+			err = writeCreditsXML(out, langs)
+		default:
+			return fmt.Errorf("unknown format %q", format)
+		}
+		if err != nil {
+			return err
+		}
+		return out.Close()
+	},
+}
+
+func init() {
+	{
+		f := contributorsCmd.Flags()
+		f.StringP("input", "i", "locales", "locales repository directory")
+		f.StringP("output", "o", "-", "output file")
+		f.StringP("format", "f", "text", "output format (text, json, credits, xml)")
+		f.StringSlice("limit", nil, "limit languages")
+	}
+	rootCmd.AddCommand(
+		contributorsCmd,
+	)
+}
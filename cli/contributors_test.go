@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// commitFiles writes files to worktree of repo in dir and commits them
+// with message by author.
+func commitFiles(t *testing.T, repo *git.Repository, dir, message string, author object.Signature, files map[string]string) *object.Commit {
+	t.Helper()
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = ioutil.WriteFile(p, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = wt.Add(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	hash, err := wt.Commit(message, &git.CommitOptions{Author: &author, Committer: &author})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+const catalogHeader = `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+`
+
+func TestAttribute(t *testing.T) {
+	var (
+		ann    = object.Signature{Name: "Ann", Email: "ann@example.com"}
+		anna   = object.Signature{Name: "Anna K", Email: "Ann@example.com"}
+		bob    = object.Signature{Name: "Bob", Email: "bob@example.com"}
+		noMail = object.Signature{Name: "Carl"}
+	)
+	for _, tt := range []struct {
+		Name     string
+		Changes  []commitChange
+		Expected map[string][]contributor
+	}{
+		{
+			Name:     "Empty",
+			Expected: map[string][]contributor{},
+		},
+		{
+			Name: "Renamed",
+			Changes: []commitChange{
+				{Author: anna, Strings: map[string]int{"ru": 2}},
+				{Author: ann, Strings: map[string]int{"ru": 3}},
+				{Author: bob, Strings: map[string]int{"ru": 4}},
+			},
+			Expected: map[string][]contributor{
+				"ru": {
+					{Name: "Anna K", Strings: 5, Commits: 2},
+					{Name: "Bob", Strings: 4, Commits: 1},
+				},
+			},
+		},
+		{
+			Name: "MultipleLocales",
+			Changes: []commitChange{
+				{Author: bob, Strings: map[string]int{"ru": 1, "de": 2, "fr": 0}},
+				{Author: noMail, Strings: map[string]int{"de": 2}},
+			},
+			Expected: map[string][]contributor{
+				"ru": {{Name: "Bob", Strings: 1, Commits: 1}},
+				"de": {
+					{Name: "Bob", Strings: 2, Commits: 1},
+					{Name: "Carl", Strings: 2, Commits: 1},
+				},
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			if got := attribute(tt.Changes); !reflect.DeepEqual(got, tt.Expected) {
+				t.Errorf("got %+v, expected %+v", got, tt.Expected)
+			}
+		})
+	}
+}
+
+func TestContributions(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	ann := object.Signature{Name: "Ann", Email: "ann@example.com", When: start}
+	commitFiles(t, repo, dir, "translate keys and tips", ann, map[string]string{
+		"ru/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Прыжок"
+
+msgctxt "Keys.Crouch"
+msgid "Crouch"
+msgstr ""
+`,
+		"ru/Tips.po": catalogHeader + `
+msgid "Press {KEY:Jump} to jump"
+msgstr "Нажмите {KEY:Jump} для прыжка"
+`,
+		"de/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Springen"
+`,
+		"README.md": "locales",
+	})
+	martian := object.Signature{Name: "Martian", Email: "martian@localhost", When: start.Add(time.Hour)}
+	commitFiles(t, repo, dir, "automated: update translations", martian, map[string]string{
+		"ru/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Прыжок"
+
+msgctxt "Keys.Crouch"
+msgid "Crouch"
+msgstr "Присесть"
+`,
+	})
+	renamed := object.Signature{Name: "Anna K", Email: "ann@example.com", When: start.Add(2 * time.Hour)}
+	commitFiles(t, repo, dir, "fix jump", renamed, map[string]string{
+		"ru/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Прыгнуть"
+
+msgctxt "Keys.Crouch"
+msgid "Crouch"
+msgstr "Присесть"
+`,
+	})
+	got, err := contributions(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]contributor{
+		"ru": {{Name: "Anna K", Strings: 3, Commits: 2}},
+		"de": {{Name: "Anna K", Strings: 1, Commits: 1}},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("got %+v, expected %+v", got, expected)
+	}
+}
@@ -1,33 +1,147 @@
 package cli
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
-	"path/filepath"
+	"path"
 	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/st-10n/martian/assets"
 	"github.com/st-10n/martian/resource"
 )
 
+// diffChanges returns changed records of english files between a and b,
+// counting languages with translation of every record.
+func diffChanges(a, b fs.FS, languages Languages, simplified []string) ([]resource.Change, error) {
+	var templates []resource.Template
+	seen := make(map[string]bool)
+	for _, fsys := range []fs.FS{a, b} {
+		fsysTemplates, err := resource.FindTemplates(fsys)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range fsysTemplates {
+			if seen[t.String()] {
+				continue
+			}
+			seen[t.String()] = true
+			templates = append(templates, t)
+		}
+	}
+	if len(templates) == 0 {
+		return nil, errors.New("no english files found in input folders")
+	}
+	var changes []resource.Change
+	for _, t := range templates {
+		read := func(fsys fs.FS) ([]byte, error) {
+			data, err := fs.ReadFile(fsys, t.String())
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, nil
+			}
+			return data, err
+		}
+		gen := func(fsys fs.FS, original []byte, lang Language) (resource.Entries, error) {
+			if len(original) == 0 {
+				return nil, nil
+			}
+			return t.Gen(fsys, lang.GetPrefix(), resource.GenOptions{
+				Simplified: simplified,
+			})
+		}
+		aOrig, err := read(a)
+		if err != nil {
+			return nil, err
+		}
+		bOrig, err := read(b)
+		if err != nil {
+			return nil, err
+		}
+		got, err := resource.Diff(aOrig, bOrig, t.FilePrefix())
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %v", t, err)
+		}
+		if len(got) == 0 {
+			continue
+		}
+		for _, lang := range languages {
+			if lang.IsEnglish() {
+				continue
+			}
+			aEntries, err := gen(a, aOrig, lang)
+			if err != nil {
+				return nil, fmt.Errorf("failed to gen %s for %s: %v", t, lang.Code, err)
+			}
+			bEntries, err := gen(b, bOrig, lang)
+			if err != nil {
+				return nil, fmt.Errorf("failed to gen %s for %s: %v", t, lang.Code, err)
+			}
+			for i, c := range got {
+				entries := aEntries
+				if c.Type == resource.Added {
+					entries = bEntries
+				}
+				for _, e := range entries {
+					if c.Affects(e) {
+						got[i].Languages++
+						break
+					}
+				}
+			}
+		}
+		changes = append(changes, got...)
+	}
+	return changes, nil
+}
+
+// changeName returns name of changed record field, like
+// "Reagents/Flour.Value".
+func changeName(c resource.Change) string {
+	name := c.File
+	if c.Key != "" {
+		name += "/" + c.Key + "." + c.Field
+	}
+	return name
+}
+
+func writeChanges(w io.Writer, changes []resource.Change) error {
+	for _, c := range changes {
+		fmt.Fprintf(w, "%s %s (translated: %d languages)\n", c.Type, changeName(c), c.Languages)
+		if c.Type != resource.Added {
+			fmt.Fprintf(w, "  - %q\n", c.Old)
+		}
+		if c.Type != resource.Removed {
+			if _, err := fmt.Fprintf(w, "  + %q\n", c.New); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 var diffCmd = &cobra.Command{
 	Use: "diff",
 	Aliases: []string{
 		"d",
 	},
-	Short: "Difference",
+	Short: "Difference between two versions of xml files",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var (
 			f = cmd.Flags()
 
 			bDir, aDir string
-			templates  []originalFile
+			format     string
+			templates  []resource.Template
 			limit      []string
 			err        error
 			languages  Languages
 			english    Language
+			entries    bool
 		)
 		if aDir, err = f.GetString("original"); err != nil {
 			return err
@@ -38,12 +152,48 @@ var diffCmd = &cobra.Command{
 		if len(bDir) == 0 {
 			return errors.New("blank modified dir")
 		}
+		aFS, aCloser, err := assets.Open(aDir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", aDir, err)
+		}
+		defer aCloser.Close()
+		bFS, bCloser, err := assets.Open(bDir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", bDir, err)
+		}
+		defer bCloser.Close()
 		if err = viper.UnmarshalKey("languages", &languages); err != nil {
 			return err
 		}
 		if limit, err = f.GetStringSlice("limit"); err != nil {
 			return err
 		}
+		if entries, err = f.GetBool("entries"); err != nil {
+			return err
+		}
+		if format, err = f.GetString("format"); err != nil {
+			return err
+		}
+		if entries {
+			selected, err := selectLanguages(limit)
+			if err != nil {
+				return err
+			}
+			changes, err := diffChanges(aFS, bFS, selected, viper.GetStringSlice("simplified"))
+			if err != nil {
+				return err
+			}
+			switch format {
+			case "text":
+				return writeChanges(os.Stdout, changes)
+			case "json":
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				return enc.Encode(changes)
+			default:
+				return fmt.Errorf("unknown format %q", format)
+			}
+		}
 		for _, lang := range languages {
 			if lang.Code == "EN" {
 				english = lang
@@ -53,20 +203,7 @@ var diffCmd = &cobra.Command{
 		if english.Code == "" {
 			return errors.New("no english language configured (code=EN)")
 		}
-		if err = filepath.Walk(aDir, func(path string, info os.FileInfo, err error) error {
-			base := filepath.Base(path)
-			relative, err := filepath.Rel(aDir, filepath.Dir(path))
-			if err != nil {
-				return err
-			}
-			if strings.HasPrefix(base, "english") && strings.HasSuffix(base, ".xml") {
-				templates = append(templates, originalFile{
-					Postfix: strings.TrimPrefix(base, "english"),
-					Path:    relative,
-				})
-			}
-			return nil
-		}); err != nil {
+		if templates, err = resource.FindTemplates(aFS); err != nil {
 			return err
 		}
 		if len(templates) == 0 {
@@ -102,17 +239,14 @@ var diffCmd = &cobra.Command{
 			//fmt.Printf("  code: %s\n", lang.Code)
 			//fmt.Printf("  locale: %s\n", lang.Locale)
 			var aEntries, bEntries resource.Entries
-			gen := func(t originalFile, dir string, e resource.Entries) (resource.Entries, error) {
-				name := lang.Prefix + t.Postfix
-				translatedPath := filepath.Join(dir, t.Path, name)
-				origPath := filepath.Join(aDir, t.Path, "english"+t.Postfix)
-				original, err := readFile(origPath)
+			gen := func(t resource.Template, dir fs.FS, e resource.Entries) (resource.Entries, error) {
+				original, err := fs.ReadFile(aFS, path.Join(t.Path, t.Name("english")))
 				if err != nil {
 					return nil, fmt.Errorf("failed to read english translation file: %v", err)
 				}
-				translated, err := readFile(translatedPath)
+				translated, err := fs.ReadFile(dir, path.Join(t.Path, t.Name(lang.Prefix)))
 				if err != nil {
-					if !(os.IsNotExist(err) && t.Postfix != ".xml") {
+					if !(errors.Is(err, fs.ErrNotExist) && t.Postfix != ".xml") {
 						return nil, fmt.Errorf("failed to find translated file for %s", lang.Code)
 					}
 				}
@@ -120,13 +254,7 @@ var diffCmd = &cobra.Command{
 					Original:   original,
 					Translated: translated,
 					Simplified: viper.GetStringSlice("simplified"),
-				}
-				// Scenario/EscapeFromMars/Language/english_mars_mission.xml -> EscapeFromMars
-				// Language -> ""
-				for _, s := range strings.Split(t.Path, string(filepath.Separator)) {
-					if s != "Language" {
-						o.FilePrefix = s
-					}
+					FilePrefix: t.FilePrefix(),
 				}
 				gotEntries, err := resource.Gen(o)
 				if err != nil {
@@ -135,11 +263,11 @@ var diffCmd = &cobra.Command{
 				return append(e, gotEntries...), nil
 			}
 			for _, t := range templates {
-				aEntries, err = gen(t, aDir, aEntries)
+				aEntries, err = gen(t, aFS, aEntries)
 				if err != nil {
 					return err
 				}
-				bEntries, err = gen(t, bDir, bEntries)
+				bEntries, err = gen(t, bFS, bEntries)
 				if err != nil {
 					return err
 				}
@@ -155,10 +283,12 @@ var diffCmd = &cobra.Command{
 func init() {
 	{
 		f := diffCmd.Flags()
-		f.StringP("original", "o", ".", "original directory")
-		f.StringP("modified", "m", ".", "modified directory")
+		f.StringP("original", "o", ".", "original directory or archive")
+		f.StringP("modified", "m", ".", "modified directory or archive")
 		f.StringSlice("limit", nil, "limit languages")
 		f.StringP("prefix", "p", "", "filename prefix")
+		f.BoolP("entries", "e", false, "list added, removed and changed english records")
+		f.StringP("format", "f", "text", "format of entries list (text, json)")
 	}
 	rootCmd.AddCommand(
 		diffCmd,
@@ -1,15 +1,19 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/st-10n/martian/assets"
 	"github.com/st-10n/martian/resource"
 )
 
@@ -24,13 +28,16 @@ var genCmd = &cobra.Command{
 			f = cmd.Flags()
 
 			outDir, inDir string
-			templates     []originalFile
+			templates     []resource.Template
 			limit         []string
 			err           error
 			languages     Languages
 			templateOnly  bool
 			english       Language
 			prefix        string
+			stampName     string
+			provenance    bool
+			same          bool
 		)
 		if prefix, err = f.GetString("prefix"); err != nil {
 			return err
@@ -62,26 +69,66 @@ var genCmd = &cobra.Command{
 		if english.Code == "" {
 			return errors.New("no english language configured (code=EN)")
 		}
-		if err = filepath.Walk(inDir, func(path string, info os.FileInfo, err error) error {
-			base := filepath.Base(path)
-			relative, err := filepath.Rel(inDir, filepath.Dir(path))
-			if err != nil {
-				return err
-			}
-			if strings.HasPrefix(base, "english") && strings.HasSuffix(base, ".xml") {
-				templates = append(templates, originalFile{
-					Postfix: strings.TrimPrefix(base, "english"),
-					Path:    relative,
-				})
-			}
-			return nil
-		}); err != nil {
+		input, inputCloser, err := assets.Open(inDir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", inDir, err)
+		}
+		defer inputCloser.Close()
+		if templates, err = resource.FindTemplates(input); err != nil {
 			return fmt.Errorf("failed to walk %s: %v", inDir, err)
 		}
 		if len(templates) == 0 {
 			return errors.New("no english files found in input folder")
 		}
 		fmt.Println("templates:", templates)
+		if stampName, err = f.GetString("stamp"); err != nil {
+			return err
+		}
+		var stamp *sourceStamp
+		if stampName != "" {
+			var stampErr error
+			stamp, stampErr = readStamp(input, stampName)
+			if stampErr != nil && !errors.Is(stampErr, fs.ErrNotExist) {
+				return stampErr
+			}
+			if stamp != nil {
+				var names []string
+				for _, t := range templates {
+					names = append(names, t.String())
+				}
+				changed, err := stamp.check(input, names)
+				if err != nil {
+					return err
+				}
+				fmt.Println("version:", stamp.Version)
+				for _, name := range changed {
+					fmt.Println("warning: not matching version stamp:", name)
+				}
+			}
+		}
+		if provenance, err = f.GetBool("provenance"); err != nil {
+			return err
+		}
+		if same, err = f.GetBool("same"); err != nil {
+			return err
+		}
+		var (
+			version string
+			commits map[resource.Template]string
+			created = time.Now().UTC().Format("2006-01-02 15:04-0700")
+		)
+		if provenance {
+			if data, readErr := fs.ReadFile(input, "version.txt"); readErr == nil {
+				version = strings.TrimSpace(string(data))
+			} else if stamp != nil {
+				version = stamp.Version
+			}
+			if stat, statErr := os.Stat(inDir); statErr == nil && stat.IsDir() {
+				if commits, err = sourceCommits(inDir, templates); err != nil {
+					return fmt.Errorf("failed to read source commits: %v", err)
+				}
+			}
+		}
 	Loop:
 		for _, lang := range languages {
 			if len(limit) > 0 {
@@ -110,37 +157,25 @@ var genCmd = &cobra.Command{
 			fmt.Printf("  prefix: %s\n", lang.Prefix)
 			fmt.Printf("  code: %s\n", lang.Code)
 			fmt.Printf("  locale: %s\n", lang.Locale)
-			var entries resource.Entries
+			var (
+				entries resource.Entries
+				sources = make(map[string][]resource.Template)
+			)
 			for _, t := range templates {
-				name := lang.Prefix + t.Postfix
-				translatedPath := filepath.Join(inDir, t.Path, name)
-				origPath := filepath.Join(inDir, t.Path, "english"+t.Postfix)
-				original, err := readFile(origPath)
-				if err != nil {
-					return fmt.Errorf("failed to read english translation file: %v", err)
-				}
-				translated, err := readFile(translatedPath)
-				if err != nil {
-					if !os.IsNotExist(err) {
-						return fmt.Errorf("failed to find translated file for %s", lang.Code)
-					}
-				}
-				o := resource.GenOptions{
-					Original:   original,
-					Translated: translated,
+				gotEntries, err := t.Gen(input, lang.Prefix, resource.GenOptions{
 					Simplified: viper.GetStringSlice("simplified"),
+					Same:       same && !lang.IsEnglish(),
+				})
+				if err != nil {
+					return fmt.Errorf("failed to gen %s: %v", t, err)
 				}
-				// Scenario/EscapeFromMars/Language/english_mars_mission.xml -> EscapeFromMars
-				// Language -> ""
-				for _, s := range strings.Split(t.Path, string(filepath.Separator)) {
-					if s != "Language" {
-						o.FilePrefix = s
+				for _, e := range gotEntries {
+					// Several templates can have the same file, like tips
+					// of scenarios.
+					if list := sources[e.File]; len(list) == 0 || list[len(list)-1] != t {
+						sources[e.File] = append(list, t)
 					}
 				}
-				gotEntries, err := resource.Gen(o)
-				if err != nil {
-					return fmt.Errorf("failed to gen: %v", err)
-				}
 				entries = append(entries, gotEntries...)
 			}
 			fmt.Printf("  entries: %d\n", entries.TranslatedCount())
@@ -154,12 +189,12 @@ var genCmd = &cobra.Command{
 			}
 			for _, name := range entries.Files() {
 				poName := fmt.Sprintf("%s.po", prefix+name)
-				_, statErr := os.Stat(path.Join(targetDir, poName))
+				previous, readErr := readFile(path.Join(targetDir, poName))
 				exists := true
-				if os.IsNotExist(statErr) {
+				if os.IsNotExist(readErr) {
 					exists = false
-				} else if statErr != nil {
-					return fmt.Errorf("failed to stat: %v", statErr)
+				} else if readErr != nil {
+					return fmt.Errorf("failed to read: %v", readErr)
 				}
 				if !templateOnly || !exists {
 					fileName := poName
@@ -193,20 +228,76 @@ var genCmd = &cobra.Command{
 				); err != nil {
 					return fmt.Errorf("failed to merge: %v", err)
 				}
+				if !provenance {
+					continue
+				}
+				var files, fileCommits []string
+				for _, t := range sources[name] {
+					files = append(files, t.String())
+					if commit := commits[t]; commit != "" && !resource.StringIn(commit, fileCommits) {
+						fileCommits = append(fileCommits, commit)
+					}
+				}
+				fields := []resource.HeaderField{
+					{Name: "POT-Creation-Date", Value: created},
+					{Name: "X-Source-File", Value: strings.Join(files, ", ")},
+				}
+				if version != "" {
+					fields = append(fields, resource.HeaderField{Name: "X-Game-Version", Value: version})
+				}
+				if len(fileCommits) > 0 {
+					fields = append(fields, resource.HeaderField{Name: "X-Source-Commit", Value: strings.Join(fileCommits, ", ")})
+				}
+				if err = setHeader(filepath.Join(targetDir, poName), previous, fields); err != nil {
+					return fmt.Errorf("failed to set header of %s: %v", poName, err)
+				}
 			}
 		}
 		return nil
 	},
 }
 
+// setHeader sets header fields of po file. POT-Creation-Date of previous
+// content is kept if nothing else is changed, so catalogs are not changed
+// by every run.
+func setHeader(name string, previous []byte, fields []resource.HeaderField) error {
+	data, err := readFile(name)
+	if err != nil {
+		return err
+	}
+	result, err := resource.SetHeader(data, fields)
+	if err != nil {
+		return err
+	}
+	if date := resource.HeaderValue(previous, "POT-Creation-Date"); date != "" {
+		kept := make([]resource.HeaderField, len(fields))
+		copy(kept, fields)
+		for i := range kept {
+			if kept[i].Name == "POT-Creation-Date" {
+				kept[i].Value = date
+			}
+		}
+		if data, err = resource.SetHeader(data, kept); err != nil {
+			return err
+		}
+		if bytes.Equal(data, previous) {
+			result = data
+		}
+	}
+	return writeFile(name, result)
+}
+
 func init() {
 	{
 		f := genCmd.Flags()
 		f.StringP("output", "o", ".", "output directory")
-		f.StringP("input", "i", ".", "input directory")
+		f.StringP("input", "i", ".", "input directory or archive (StreamingAssets)")
 		f.StringSlice("limit", nil, "limit languages")
 		f.BoolP("template", "t", true, "generate templates (.pot) only")
 		f.StringP("prefix", "p", "", "filename prefix")
+		f.String("stamp", "sources.json", "version stamp file written by update (relative to input if not absolute)")
+		f.Bool("provenance", true, "record game version, source commit and generation time in headers")
+		f.Bool("same", false, "mark game translations that are identical to english as {SAME}")
 	}
 	rootCmd.AddCommand(
 		genCmd,
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/st-10n/martian/assets"
+	"github.com/st-10n/martian/resource"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+// headHash returns hash of HEAD commit of git repository in dir.
+func headHash(dir string) (plumbing.Hash, error) {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	ref, err := repo.Head()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return ref.Hash(), nil
+}
+
+// worktreeRoot returns absolute root directory of git worktree that
+// contains dir, or blank string if there is no such worktree.
+func worktreeRoot(dir string) (string, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err == git.ErrRepositoryNotExists {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Abs(wt.Filesystem.Root())
+}
+
+// readCommitCatalogs reads all PO files from dir of commit tree, like
+// readCatalogs does for working directory.
+func readCommitCatalogs(c *object.Commit, dir string) (resource.Entries, error) {
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+	var entries resource.Entries
+	if err = tree.Files().ForEach(func(f *object.File) error {
+		if path.Dir(f.Name) != dir || !strings.HasSuffix(f.Name, ".po") {
+			return nil
+		}
+		data, err := f.Contents()
+		if err != nil {
+			return err
+		}
+		got, err := resource.ReadCatalog(strings.TrimSuffix(path.Base(f.Name), ".po"), []byte(data))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", f.Name, err)
+		}
+		entries = append(entries, got...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// fileCommit returns last commit on the first-parent history of HEAD
+// that changed file with slash-separated worktree-relative name.
+func fileCommit(repo *git.Repository, name string) (*object.Commit, error) {
+	ref, err := repo.Head()
+	if err != nil {
+		return nil, err
+	}
+	c, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.File(name)
+	if err != nil {
+		return nil, err
+	}
+	for c.NumParents() > 0 {
+		parent, err := c.Parent(0)
+		if err != nil {
+			return nil, err
+		}
+		parentFile, err := parent.File(name)
+		if err == object.ErrFileNotFound {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parentFile.Hash != f.Hash {
+			break
+		}
+		c = parent
+	}
+	return c, nil
+}
+
+// sourceCommits returns hashes of last commits that changed templates in
+// git repository of assets directory, see fileCommit. Templates that are
+// changed in worktree have "-dirty" suffix, and not committed ones are
+// skipped.
+//
+// Returns nil if dir is not in git repository.
+func sourceCommits(dir string, templates []resource.Template) (map[resource.Template]string, error) {
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{
+		DetectDotGit: true,
+	})
+	if err == git.ErrRepositoryNotExists {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	root, err := filepath.Abs(wt.Filesystem.Root())
+	if err != nil {
+		return nil, err
+	}
+	assetsRoot, err := assets.Find(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(filepath.Join(dir, assetsRoot))
+	if err != nil {
+		return nil, err
+	}
+	commits := make(map[resource.Template]string, len(templates))
+	for _, t := range templates {
+		name := filepath.Join(absDir, filepath.FromSlash(t.String()))
+		rel, err := filepath.Rel(root, name)
+		if err != nil {
+			return nil, err
+		}
+		c, err := fileCommit(repo, filepath.ToSlash(rel))
+		if err == object.ErrFileNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to find commit of %s: %v", t, err)
+		}
+		hash := c.Hash.String()
+		data, err := readFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f, err := c.File(filepath.ToSlash(rel))
+		if err != nil {
+			return nil, err
+		}
+		if plumbing.ComputeHash(plumbing.BlobObject, data) != f.Hash {
+			hash += "-dirty"
+		}
+		commits[t] = hash
+	}
+	return commits, nil
+}
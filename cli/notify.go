@@ -1,18 +1,18 @@
 package cli
 
 import (
-	"errors"
+	"context"
 	"fmt"
-	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
-	discord "github.com/bwmarrin/discordgo"
 	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/st-10n/martian/notify"
+	"github.com/st-10n/martian/vcs"
 	"gopkg.in/src-d/go-git.v4"
 	"gopkg.in/src-d/go-git.v4/plumbing/object"
 	"gopkg.in/src-d/go-git.v4/plumbing/storer"
@@ -25,30 +25,68 @@ var notifyCmd = &cobra.Command{
 	},
 }
 
-func getDiscord() (*discord.Session, error) {
-	t := viper.GetString("discord.token")
-	if len(t) == 0 {
-		return nil, errors.New("no discord token provided")
-	}
-	s, err := discord.New("Bot " + t)
-	if err != nil {
+const footer = "Generated by Martian, the Great Localization Robot"
+
+// defaultChannels are discord channels of notifications by name, used if
+// neither channel of notification nor channel of notifier is configured.
+var defaultChannels = map[string]string{
+	"assets": "495320457491251201",
+	"error":  "495483352841977867",
+}
+
+// getNotifier returns notifier for all backends configured in "notifiers",
+// or for discord if none are configured.
+//
+// The channel of notification kind, like "notify.error.channel", takes
+// precedence over channel of discord backends if set, and fallback is used
+// if neither is set.
+func getNotifier(channel, fallback string) (notify.Notifier, error) {
+	var configs []notify.Config
+	if err := viper.UnmarshalKey("notifiers", &configs); err != nil {
 		return nil, err
 	}
-	return s, nil
+	return newNotifier(configs, channel, fallback)
 }
 
-const (
-	colorGreen = 0x228B22
-	colorRed   = 0xff2400
-)
+// newNotifier returns notifier for configs, see getNotifier.
+func newNotifier(configs []notify.Config, channel, fallback string) (notify.Notifier, error) {
+	var multi notify.Multi
+	if len(configs) == 0 {
+		configs = append(configs, notify.Config{Type: "discord"})
+	}
+	for _, c := range configs {
+		if c.Type == "discord" {
+			if c.Token == "" {
+				c.Token = viper.GetString("discord.token")
+			}
+			switch {
+			case channel != "":
+				c.Channel = channel
+			case c.Channel == "":
+				c.Channel = fallback
+			}
+		}
+		n, err := notify.New(c)
+		if err != nil {
+			return nil, err
+		}
+		multi = append(multi, n)
+	}
+	return multi, nil
+}
 
 var notifyNewAssets = &cobra.Command{
 	Use: "assets",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		var (
 			languages Languages
+			cfg       = getNotification("assets")
 		)
-		d, err := getDiscord()
+		withChangelog, err := cmd.Flags().GetBool("changelog")
+		if err != nil {
+			return err
+		}
+		n, err := getNotifier(cfg.Channel, defaultChannels["assets"])
 		if err != nil {
 			return err
 		}
@@ -76,34 +114,40 @@ var notifyNewAssets = &cobra.Command{
 			return err
 		}
 		folders := make(map[string][]string)
-		langChanged := make(map[Language]bool)
+		langFiles := make(map[Language][]string)
 		if err = resCommit.Parents().ForEach(func(commit *object.Commit) error {
 			patch, err := resCommit.Patch(commit)
 			if err != nil {
 				return err
 			}
+			hasEnglish := func(name string) bool {
+				// Checking parent for removed files.
+				for _, c := range []*object.Commit{resCommit, commit} {
+					if _, err := c.File(name); err == nil {
+						return true
+					}
+				}
+				return false
+			}
 			for _, p := range patch.FilePatches() {
 				from, to := p.Files()
-				var searchIn []string
-				if from != nil {
-					searchIn = append(searchIn, from.Path())
+				var name string
+				switch {
+				case to != nil:
+					name = to.Path()
+				case from != nil:
+					name = from.Path()
 				}
-				if to != nil {
-					searchIn = append(searchIn, to.Path())
+				if !strings.HasSuffix(name, ".xml") {
+					continue
 				}
-				for _, name := range searchIn {
-					if strings.HasSuffix(name, ".xml") {
-						dir := filepath.Dir(name)
-						base := filepath.Base(name)
-						folders[dir] = append(folders[dir], strings.TrimSuffix(base, ".xml"))
-
-						for _, l := range languages {
-							if strings.HasPrefix(base, l.GetPrefix()) {
-								langChanged[l] = true
-							}
-						}
+				dir := filepath.Dir(name)
+				base := filepath.Base(name)
+				folders[dir] = append(folders[dir], strings.TrimSuffix(base, ".xml"))
+				for _, l := range languages {
+					if isLanguageFile(name, l, hasEnglish) {
+						langFiles[l] = append(langFiles[l], strings.TrimSuffix(name, ".xml"))
 					}
-					return nil
 				}
 			}
 			return nil
@@ -126,7 +170,7 @@ var notifyNewAssets = &cobra.Command{
 			return err
 		}
 		if err = iter.ForEach(func(commit *object.Commit) error {
-			if !strings.HasPrefix(commit.Message, "automated") {
+			if !strings.HasPrefix(commit.Message, vcs.Prefix) {
 				latest = commit.Author.When
 				return storer.ErrStop
 			}
@@ -138,84 +182,109 @@ var notifyNewAssets = &cobra.Command{
 		if err != nil {
 			return err
 		}
-		f, err := os.Open("StreamingAssets.zip")
+		zipData, err := readFile("StreamingAssets.zip")
 		if err != nil {
 			return fmt.Errorf("failed to open StreamingAssets: %v", err)
 		}
-		defer f.Close()
-		df := &discord.File{
+		file := notify.File{
 			Name: fmt.Sprintf("StreamingAssets-R%s-L%s.zip",
 				resRef.Hash().String()[:7],
 				ref.Hash().String()[:7],
 			),
 			ContentType: "application/zip",
-			Reader:      f,
-		}
-		description := new(strings.Builder)
-		fmt.Fprintln(description, "The assets were automatically generated.")
-		fmt.Fprint(description, "\n")
-		if len(folders) == 0 {
-			fmt.Fprintln(description, "**No xml files changed.**")
-		} else {
-			var foldersList []string
-			for k := range folders {
-				foldersList = append(foldersList, k)
+			Data:        zipData,
+		}
+		data := assetsData{
+			Version:      ver,
+			Resources:    newCommitInfo(resCommit, viper.GetString("notify.repositories.resources")),
+			Locales:      newCommitInfo(commit, viper.GetString("notify.repositories.locales")),
+			LatestUpdate: latest,
+		}
+		for name, files := range folders {
+			data.Folders = append(data.Folders, folderChange{
+				Name:  name,
+				Files: files,
+			})
+		}
+		sort.Slice(data.Folders, func(i, j int) bool {
+			return data.Folders[i].Name < data.Folders[j].Name
+		})
+		for _, l := range languages {
+			if len(langFiles[l]) > 0 {
+				data.Languages = append(data.Languages, l.Name)
 			}
-			sort.Strings(foldersList)
-			fmt.Fprintln(description, "**Files changed:**")
-			for _, folder := range foldersList {
-				fmt.Fprintf(description, "[%s]: %d\n", folder, len(folders[folder]))
+		}
+		sort.Strings(data.Languages)
+		var resParent *object.Commit
+		if resCommit.NumParents() > 0 {
+			if resParent, err = resCommit.Parent(0); err != nil {
+				return err
 			}
-			if len(langChanged) > 0 {
-				var affected []string
-				for _, l := range languages {
-					if langChanged[l] {
-						affected = append(affected, l.Name)
-					}
-				}
-				sort.Strings(affected)
-				fmt.Fprint(description, "\n")
-				fmt.Fprintln(description, "**Languages affected:**", strings.Join(affected, ", "))
+		}
+		locales := make(map[Language]localeRange)
+		for _, l := range languages {
+			if len(langFiles[l]) == 0 || l.IsEnglish() {
+				continue
+			}
+			// Comparing catalogs that baked files are generated from.
+			r, err := bakedLocales(repo, commit, resCommit, resParent, langFiles[l])
+			if err != nil {
+				return err
+			}
+			locales[l] = r
+			log, err := newChangelog(l, r.Old, r.New)
+			if err != nil {
+				return err
 			}
+			if log.Authors, err = changeAuthors(repo, r, l, log.Changes); err != nil {
+				return fmt.Errorf("failed to read locale authors: %v", err)
+			}
+			data.Changelog = append(data.Changelog, log)
 		}
-		fmt.Fprintf(description, "\nSee raw commit:\nhttps://github.com/st-l10n/resources/commit/%s", resRef.Hash().String()[:7])
-		descriptionStr := description.String()
-		if description.Len() > 2000 {
-			descriptionStr = descriptionStr[:2000] + "..."
-		}
-		embed := &discord.MessageEmbed{
-			Color:       colorGreen,
-			Title:       "New assets",
-			Description: descriptionStr,
-			Footer: &discord.MessageEmbedFooter{
-				Text: "Generated by Martian, the Great Localization Robot",
-			},
+		title, description, err := cfg.render(data)
+		if err != nil {
+			return err
 		}
-		embed.Fields = []*discord.MessageEmbedField{
-			{
-				Name:  "Version",
-				Value: "v" + ver,
-			},
-			{
-				Name:  "Locale commit",
-				Value: commit.Message,
+		if file.URL, err = cfg.artifactURL(ver, file.Name); err != nil {
+			return err
+		}
+		m := notify.Message{
+			Color:       notify.ColorGreen,
+			Title:       title,
+			Description: description,
+			Footer:      footer,
+			Fields: []notify.Field{
+				{
+					Name:  "Version",
+					Value: "v" + ver,
+				},
+				{
+					Name:  "Locale commit",
+					Value: commit.Message,
+				},
+				{
+					Name:  "Locale latest update",
+					Value: humanize.Time(latest),
+				},
 			},
-			{
-				Name:  "Locale latest update",
-				Value: humanize.Time(latest),
+			Files: []notify.File{
+				file,
 			},
 		}
-		mes := &discord.MessageSend{
-			Embed: embed,
-			Files: []*discord.File{
-				df,
-			},
+		if withChangelog {
+			m.Files = append(m.Files, notify.File{
+				Name:        fmt.Sprintf("CHANGELOG-v%s.md", ver),
+				ContentType: "text/markdown",
+				Data:        changelogMarkdown(ver, data.Changelog),
+			})
 		}
-		st, err := d.ChannelMessageSendComplex("495320457491251201", mes)
-		if err != nil {
+		if err = n.Notify(context.Background(), m); err != nil {
 			return fmt.Errorf("failed to send message: %v", err)
 		}
-		fmt.Println("OK", st.ID)
+		if err = notifyLanguages(data, languages, langFiles, locales); err != nil {
+			return err
+		}
+		fmt.Println("OK")
 		return nil
 	},
 }
@@ -224,28 +293,32 @@ var notifyErrCmd = &cobra.Command{
 	Use:   "error",
 	Short: "Notify about error",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		sess, err := getDiscord()
+		cfg := getNotification("error")
+		n, err := getNotifier(cfg.Channel, defaultChannels["error"])
 		if err != nil {
 			return err
 		}
-		embed := &discord.MessageEmbed{
-			Color:       colorRed,
-			Title:       viper.GetString("notification.title"),
-			Description: viper.GetString("notification.text"),
-			Footer: &discord.MessageEmbedFooter{
-				Text: "Generated by Martian, the Great Localization Robot",
-			},
+		title, text, err := cfg.render(errorData{
+			Title: viper.GetString("notification.title"),
+			Text:  viper.GetString("notification.text"),
+			Task:  viper.GetString("notification.task"),
+		})
+		if err != nil {
+			return err
 		}
-		embed.Fields = []*discord.MessageEmbedField{
-			{
-				Name:  "Task",
-				Value: viper.GetString("notification.task"),
+		m := notify.Message{
+			Color:       notify.ColorRed,
+			Title:       title,
+			Description: text,
+			Footer:      footer,
+			Fields: []notify.Field{
+				{
+					Name:  "Task",
+					Value: viper.GetString("notification.task"),
+				},
 			},
 		}
-		mes := &discord.MessageSend{
-			Embed: embed,
-		}
-		if _, err = sess.ChannelMessageSendComplex("495483352841977867", mes); err != nil {
+		if err = n.Notify(context.Background(), m); err != nil {
 			return fmt.Errorf("failed to send notification: %v", err)
 		}
 		return nil
@@ -259,6 +332,10 @@ func init() {
 		notifyNewAssets,
 		notifyErrCmd,
 	)
+	{
+		f := notifyNewAssets.Flags()
+		f.Bool("changelog", false, "Attach markdown changelog")
+	}
 	{
 		f := notifyErrCmd.Flags()
 		f.String("title", "Task failed", "Notification title")
@@ -272,6 +349,14 @@ func init() {
 	rootCmd.AddCommand(
 		notifyCmd,
 	)
+	viper.SetDefault("notify.assets.title", defaultAssetsTitle)
+	viper.SetDefault("notify.assets.text", defaultAssetsText)
+	viper.SetDefault("notify.language.title", defaultLanguageTitle)
+	viper.SetDefault("notify.language.text", defaultLanguageText)
+	viper.SetDefault("notify.error.title", "{{.Title}}")
+	viper.SetDefault("notify.error.text", "{{.Text}}")
+	viper.SetDefault("notify.repositories.resources", "https://github.com/st-l10n/resources")
+	viper.SetDefault("notify.repositories.locales", "https://github.com/st-l10n/locales")
 	viper.BindPFlag("discord.token", persistentFlags.Lookup("token"))
 	viper.BindEnv("discord.token", "DISCORD_TOKEN")
 }
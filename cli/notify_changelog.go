@@ -0,0 +1,298 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/st-10n/martian/resource"
+	"github.com/st-10n/martian/vcs"
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/storer"
+)
+
+// languageChangelog summarises translation changes of language between
+// resource commit and its parent.
+type languageChangelog struct {
+	Language   Language
+	Translated int // newly translated entries
+	Revised    int
+	Removed    int
+	Authors    []string // authors of changes, see changeAuthors
+	Changes    []resource.Change
+}
+
+// readCommitFile returns contents of file in commit tree, or nil if there
+// is no such file.
+func readCommitFile(c *object.Commit, name string) ([]byte, error) {
+	f, err := c.File(name)
+	if err == object.ErrFileNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	data, err := f.Contents()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(data), nil
+}
+
+// localeCommitRe matches locales commit in provenance comment of baked
+// file, see bake.
+var localeCommitRe = regexp.MustCompile(`from locales commit ([0-9a-f]{40})`)
+
+// bakedLocaleCommit returns locales commit that language files (without
+// extension, like "Language/russian_keys") of resource commit are baked
+// from, or nil if it is not recorded or not found.
+func bakedLocaleCommit(locales *git.Repository, c *object.Commit, files []string) (*object.Commit, error) {
+	for _, name := range files {
+		data, err := readCommitFile(c, name+".xml")
+		if err != nil {
+			return nil, err
+		}
+		m := localeCommitRe.FindSubmatch(data)
+		if m == nil {
+			continue
+		}
+		commit, err := locales.CommitObject(plumbing.NewHash(string(m[1])))
+		if err == plumbing.ErrObjectNotFound {
+			return nil, nil
+		}
+		return commit, err
+	}
+	return nil, nil
+}
+
+// localeCommitAt returns the last locales commit from head that is
+// committed not after t, or nil.
+func localeCommitAt(locales *git.Repository, head *object.Commit, t time.Time) (*object.Commit, error) {
+	iter, err := locales.Log(&git.LogOptions{
+		From: head.Hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var found *object.Commit
+	if err = iter.ForEach(func(c *object.Commit) error {
+		if !c.Committer.When.After(t) {
+			found = c
+			return storer.ErrStop
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+// localeRange is locales commits that language files are baked from
+// before and after resource commit.
+type localeRange struct {
+	Old *object.Commit // nil for root resource commit
+	New *object.Commit
+}
+
+// bakedLocales returns locales commits that language files of resource
+// commit c and of its parent are baked from, see bakedLocaleCommit. If not
+// recorded, head is used for c and the last locales commit before parent
+// was committed is used for parent, which can be nil for root commit.
+func bakedLocales(locales *git.Repository, head, c, parent *object.Commit, files []string) (localeRange, error) {
+	var (
+		r   localeRange
+		err error
+	)
+	if r.New, err = bakedLocaleCommit(locales, c, files); err != nil {
+		return r, err
+	}
+	if r.New == nil {
+		r.New = head
+	}
+	if parent == nil {
+		return r, nil
+	}
+	if r.Old, err = bakedLocaleCommit(locales, parent, files); err != nil {
+		return r, err
+	}
+	if r.Old == nil {
+		r.Old, err = localeCommitAt(locales, r.New, parent.Committer.When)
+	}
+	return r, err
+}
+
+// newChangelog returns changes of translations of language between
+// locales commits, see resource.DiffCatalogs. The old commit can be nil,
+// so all translations are new.
+//
+// Catalogs are compared instead of baked files, so english fallback and
+// Same are not counted as translations and revised tips are not counted
+// as removed and added ones.
+func newChangelog(l Language, old, new *object.Commit) (languageChangelog, error) {
+	log := languageChangelog{
+		Language: l,
+	}
+	var oldEntries, newEntries resource.Entries
+	if old != nil {
+		var err error
+		if oldEntries, err = readCommitCatalogs(old, l.GetLocale()); err != nil {
+			return log, err
+		}
+	}
+	newEntries, err := readCommitCatalogs(new, l.GetLocale())
+	if err != nil {
+		return log, err
+	}
+	log.Changes = resource.DiffCatalogs(oldEntries, newEntries)
+	for _, ch := range log.Changes {
+		switch ch.Type {
+		case resource.Added:
+			log.Translated++
+		case resource.Modified:
+			log.Revised++
+		case resource.Removed:
+			log.Removed++
+		}
+	}
+	return log, nil
+}
+
+// changedFiles returns names of files changed by commit, or all files
+// for root commit.
+func changedFiles(c *object.Commit) ([]string, error) {
+	var names []string
+	if c.NumParents() == 0 {
+		tree, err := c.Tree()
+		if err != nil {
+			return nil, err
+		}
+		err = tree.Files().ForEach(func(f *object.File) error {
+			names = append(names, f.Name)
+			return nil
+		})
+		return names, err
+	}
+	stats, err := c.Stats()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stats {
+		names = append(names, s.Name)
+	}
+	return names, nil
+}
+
+// introduces reports whether change of single commit results in the
+// change between locales commits, see changeAuthors.
+func introduces(commit, result resource.Change) bool {
+	if commit.File != result.File || commit.Part != result.Part || commit.Key != result.Key || commit.Field != result.Field {
+		return false
+	}
+	if result.Type == resource.Removed {
+		return commit.Type == resource.Removed && commit.Old == result.Old
+	}
+	return commit.Type != resource.Removed && commit.New == result.New
+}
+
+// changeAuthors returns sorted names of authors of non-automated locale
+// commits between range that introduced changes of language, so authors
+// of reverted or superseded translations are not listed.
+func changeAuthors(locales *git.Repository, r localeRange, l Language, changes []resource.Change) ([]string, error) {
+	if len(changes) == 0 {
+		return nil, nil
+	}
+	iter, err := locales.Log(&git.LogOptions{
+		From: r.New.Hash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var (
+		seen    = make(map[string]bool)
+		authors []string
+		dir     = l.GetLocale()
+	)
+	if err = iter.ForEach(func(c *object.Commit) error {
+		if r.Old != nil && (c.Hash == r.Old.Hash || !c.Committer.When.After(r.Old.Committer.When)) {
+			return storer.ErrStop
+		}
+		if c.NumParents() > 1 || strings.HasPrefix(c.Message, vcs.Prefix) || seen[c.Author.Name] {
+			return nil
+		}
+		names, err := changedFiles(c)
+		if err != nil {
+			return err
+		}
+		touched := false
+		for _, name := range names {
+			if strings.HasPrefix(name, dir+"/") {
+				touched = true
+			}
+		}
+		if !touched {
+			return nil
+		}
+		var parentEntries resource.Entries
+		if c.NumParents() > 0 {
+			parent, err := c.Parent(0)
+			if err != nil {
+				return err
+			}
+			if parentEntries, err = readCommitCatalogs(parent, dir); err != nil {
+				return err
+			}
+		}
+		entries, err := readCommitCatalogs(c, dir)
+		if err != nil {
+			return err
+		}
+		for _, ch := range resource.DiffCatalogs(parentEntries, entries) {
+			for _, change := range changes {
+				if introduces(ch, change) {
+					seen[c.Author.Name] = true
+					authors = append(authors, c.Author.Name)
+					return nil
+				}
+			}
+		}
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	sort.Strings(authors)
+	return authors, nil
+}
+
+func escapeMarkdownCell(s string) string {
+	s = strings.Replace(s, "|", "\\|", -1)
+	return strings.Replace(s, "\n", "<br>", -1)
+}
+
+// changelogMarkdown returns changelog in markdown.
+func changelogMarkdown(version string, logs []languageChangelog) []byte {
+	b := new(bytes.Buffer)
+	fmt.Fprintf(b, "# Changelog v%s\n", version)
+	for _, l := range logs {
+		fmt.Fprintf(b, "\n## %s\n\n", l.Language.Name)
+		fmt.Fprintf(b, "%d translated, %d revised, %d removed.\n", l.Translated, l.Revised, l.Removed)
+		if len(l.Authors) > 0 {
+			fmt.Fprintf(b, "\nAuthors: %s\n", strings.Join(l.Authors, ", "))
+		}
+		if len(l.Changes) == 0 {
+			continue
+		}
+		fmt.Fprintln(b, "\n| Change | Field | Old | New |")
+		fmt.Fprintln(b, "|--------|-------|-----|-----|")
+		for _, c := range l.Changes {
+			fmt.Fprintf(b, "| %s | %s | %s | %s |\n", c.Type, changeName(c),
+				escapeMarkdownCell(c.Old), escapeMarkdownCell(c.New),
+			)
+		}
+	}
+	return b.Bytes()
+}
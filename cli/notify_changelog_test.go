@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+func TestNewChangelog(t *testing.T) {
+	dir := t.TempDir()
+	locales, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) object.Signature {
+		return object.Signature{Name: "Translator", Email: "t@localhost", When: start.Add(d)}
+	}
+	first := commitFiles(t, locales, dir, "update", at(0), map[string]string{
+		"ru/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Прыжок"
+
+msgctxt "Keys.Ok"
+msgid "OK"
+msgstr ""
+`,
+		"ru/Tips.po": catalogHeader + `
+msgid "Press {KEY:Jump} to jump"
+msgstr "Нажмите {KEY:Jump} для прыжка"
+`,
+	})
+	second := commitFiles(t, locales, dir, "update", at(time.Hour), map[string]string{
+		"ru/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Прыжок"
+
+msgctxt "Keys.Ok"
+msgid "OK"
+msgstr "{SAME}"
+
+msgctxt "Keys.Crouch"
+msgid "Crouch"
+msgstr "Присесть"
+`,
+		"ru/Tips.po": catalogHeader + `
+msgid "Press {KEY:Jump} to jump"
+msgstr "Нажмите {KEY:Jump}, чтобы прыгнуть"
+`,
+		"de/Keys.po": catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "Springen"
+`,
+	})
+	ru := Language{Code: "RU", Name: "Russian"}
+	log, err := newChangelog(ru, first, second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Translated != 1 || log.Revised != 1 || log.Removed != 0 || len(log.Changes) != 2 {
+		t.Errorf("unexpected changelog %+v", log)
+	}
+	if log, err = newChangelog(ru, nil, first); err != nil {
+		t.Fatal(err)
+	}
+	if log.Translated != 2 {
+		t.Errorf("unexpected changelog %+v", log)
+	}
+
+	resDir := t.TempDir()
+	resources, err := git.PlainInit(resDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	baked := commitFiles(t, resources, resDir, "update", at(2*time.Hour), map[string]string{
+		"Language/russian.xml": "<!-- Generated by martian from locales commit " + first.Hash.String() + " -->\n<Language/>\n",
+	})
+	c, err := bakedLocaleCommit(locales, baked, []string{"Language/russian"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c == nil || c.Hash != first.Hash {
+		t.Errorf("unexpected baked locale commit %v", c)
+	}
+	if c, err = localeCommitAt(locales, second, start.Add(30*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if c == nil || c.Hash != first.Hash {
+		t.Errorf("unexpected locale commit %v", c)
+	}
+	rebaked := commitFiles(t, resources, resDir, "update", at(3*time.Hour), map[string]string{
+		"Language/russian.xml": "<!-- Generated by martian from locales commit " + second.Hash.String() + " -->\n<Language/>\n",
+	})
+	r, err := bakedLocales(locales, second, rebaked, baked, []string{"Language/russian"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Old == nil || r.Old.Hash != first.Hash || r.New == nil || r.New.Hash != second.Hash {
+		t.Errorf("unexpected locale range %+v", r)
+	}
+	for _, tt := range []struct {
+		Commit   *object.Commit
+		Expected int
+	}{
+		{Commit: r.Old, Expected: 1},
+		{Commit: r.New, Expected: 0},
+	} {
+		n, err := untranslated(tt.Commit, ru)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != tt.Expected {
+			t.Errorf("%s: unexpected untranslated count %d", tt.Commit.Hash, n)
+		}
+	}
+}
+
+func TestChangeAuthors(t *testing.T) {
+	dir := t.TempDir()
+	locales, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	keys := func(jump, crouch, walk string) string {
+		return catalogHeader + `
+msgctxt "Keys.Jump"
+msgid "Jump"
+msgstr "` + jump + `"
+
+msgctxt "Keys.Crouch"
+msgid "Crouch"
+msgstr "` + crouch + `"
+
+msgctxt "Keys.Walk"
+msgid "Walk"
+msgstr "` + walk + `"
+`
+	}
+	var commits []*object.Commit
+	for i, c := range []struct {
+		Author string
+		Files  map[string]string
+	}{
+		{Author: "Translator", Files: map[string]string{"ru/Keys.po": keys("Прыжок", "", "")}},
+		{Author: "Ann", Files: map[string]string{"ru/Keys.po": keys("Прыжок", "Присесть", "")}},
+		// Reverted by Carl.
+		{Author: "Bob", Files: map[string]string{"ru/Keys.po": keys("Прыг", "Присесть", "")}},
+		{Author: "Carl", Files: map[string]string{"ru/Keys.po": keys("Прыжок", "Присесть", "")}},
+		// Superseded by Frank.
+		{Author: "Eve", Files: map[string]string{"ru/Keys.po": keys("Прыжок", "Присесть", "Шагать")}},
+		{Author: "Dan", Files: map[string]string{"de/Keys.po": catalogHeader}},
+		{Author: "Frank", Files: map[string]string{"ru/Keys.po": keys("Прыжок", "Присесть", "Шаг")}},
+	} {
+		author := object.Signature{Name: c.Author, Email: strings.ToLower(c.Author) + "@localhost", When: start.Add(time.Duration(i) * time.Hour)}
+		commits = append(commits, commitFiles(t, locales, dir, "update", author, c.Files))
+	}
+	ru := Language{Code: "RU", Name: "Russian"}
+	r := localeRange{Old: commits[0], New: commits[len(commits)-1]}
+	log, err := newChangelog(ru, r.Old, r.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if log.Translated != 2 || len(log.Changes) != 2 {
+		t.Fatalf("unexpected changelog %+v", log)
+	}
+	authors, err := changeAuthors(locales, r, ru, log.Changes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"Ann", "Frank"}; !reflect.DeepEqual(authors, expected) {
+		t.Errorf("unexpected authors %v", authors)
+	}
+}
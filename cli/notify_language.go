@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/viper"
+	"github.com/st-10n/martian/notify"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const defaultLanguageTitle = "New strings for {{.Language.Name}}"
+
+const defaultLanguageText = `{{if .Files -}}
+**Files changed:**
+{{range .Files -}}
+{{.}}
+{{end -}}
+{{end}}
+**Untranslated:** {{.Untranslated}}
+{{- if gt .NewUntranslated 0}} (+{{.NewUntranslated}}){{end}}
+{{- with .Resources.URL}}
+
+See raw commit:
+{{.}}
+{{- end}}`
+
+// languageTarget is notification target of language team, configured
+// in "notify.languages" by language code.
+type languageTarget struct {
+	// Mention is like "<@&role>" or "<@user>" for discord.
+	Mention string `mapstructure:"mention"`
+	// Channel is discord channel, "notify.assets.channel" by default.
+	Channel string `mapstructure:"channel"`
+	// Notifiers are used instead of "notifiers" if set.
+	Notifiers []notify.Config `mapstructure:"notifiers"`
+}
+
+// languageData is passed to "notify.language" templates.
+type languageData struct {
+	assetsData
+	Language        Language
+	Mention         string
+	Files           []string // changed files, like "Language/russian_keys"
+	Untranslated    int
+	NewUntranslated int // untranslated count change since previous assets
+}
+
+// isLanguageFile reports whether resource file with slash-separated name
+// belongs to language, like "Language/russian_keys.xml" for Russian.
+//
+// The rest of base name after language prefix must be postfix of english
+// file in the same directory, as reported by hasEnglish, so
+// "portuguese_brazil.xml" does not belong to Portuguese. English files
+// belong to no language.
+func isLanguageFile(name string, l Language, hasEnglish func(name string) bool) bool {
+	if l.IsEnglish() {
+		return false
+	}
+	dir, base := path.Split(name)
+	prefix := l.GetPrefix()
+	if !strings.HasPrefix(base, prefix+"_") && !strings.HasPrefix(base, prefix+".") {
+		return false
+	}
+	return hasEnglish(dir + "english" + strings.TrimPrefix(base, prefix))
+}
+
+// untranslated returns count of untranslated entries of language in
+// locale commit.
+func untranslated(c *object.Commit, l Language) (int, error) {
+	entries, err := readCommitCatalogs(c, l.GetLocale())
+	if err != nil {
+		return 0, err
+	}
+	return entries.Stats().Untranslated, nil
+}
+
+// notifyLanguages sends notifications to teams of changed languages that
+// have target in "notify.languages". Untranslated entries are counted in
+// locales commits that language files are baked from, like in changelog.
+func notifyLanguages(data assetsData, languages Languages, files map[Language][]string, locales map[Language]localeRange) error {
+	var (
+		targets    map[string]languageTarget
+		globalCfgs []notify.Config
+		cfg        = getNotification("language")
+	)
+	if err := viper.UnmarshalKey("notify.languages", &targets); err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	if err := viper.UnmarshalKey("notifiers", &globalCfgs); err != nil {
+		return err
+	}
+	for _, l := range languages {
+		if len(files[l]) == 0 {
+			continue
+		}
+		// Viper keys are case-insensitive.
+		target, ok := targets[strings.ToLower(l.Code)]
+		if !ok {
+			continue
+		}
+		d := languageData{
+			assetsData: data,
+			Language:   l,
+			Mention:    target.Mention,
+			Files:      files[l],
+		}
+		var err error
+		r := locales[l]
+		if d.Untranslated, err = untranslated(r.New, l); err != nil {
+			return fmt.Errorf("failed to count untranslated %s: %v", l.Name, err)
+		}
+		d.NewUntranslated = d.Untranslated
+		if r.Old != nil {
+			prev, err := untranslated(r.Old, l)
+			if err != nil {
+				return fmt.Errorf("failed to count untranslated %s: %v", l.Name, err)
+			}
+			d.NewUntranslated -= prev
+		}
+		title, text, err := cfg.render(d)
+		if err != nil {
+			return err
+		}
+		configs := target.Notifiers
+		if len(configs) == 0 {
+			configs = globalCfgs
+		}
+		channel := target.Channel
+		if channel == "" {
+			channel = viper.GetString("notify.assets.channel")
+		}
+		n, err := newNotifier(configs, channel, defaultChannels["assets"])
+		if err != nil {
+			return err
+		}
+		if err = n.Notify(context.Background(), notify.Message{
+			Color:       notify.ColorGreen,
+			Title:       title,
+			Description: text,
+			Footer:      footer,
+			Mention:     target.Mention,
+			Fields: []notify.Field{
+				{
+					Name:  "Version",
+					Value: "v" + data.Version,
+				},
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to send message for %s: %v", l.Name, err)
+		}
+	}
+	return nil
+}
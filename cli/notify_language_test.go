@@ -0,0 +1,40 @@
+package cli
+
+import "testing"
+
+func TestIsLanguageFile(t *testing.T) {
+	english := map[string]bool{
+		"Language/english.xml":                    true,
+		"Language/english_keys.xml":               true,
+		"Scenario/Mars/Language/english_mars.xml": true,
+	}
+	hasEnglish := func(name string) bool {
+		return english[name]
+	}
+	var (
+		en = Language{Code: "EN", Name: "English"}
+		pt = Language{Code: "PT", Name: "Portuguese"}
+		br = Language{Code: "PB", Name: "Portuguese Brazil", Locale: "pt-BR"}
+	)
+	for _, tt := range []struct {
+		Name     string
+		Language Language
+		Expected bool
+	}{
+		{Name: "Language/portuguese.xml", Language: pt, Expected: true},
+		{Name: "Language/portuguese_keys.xml", Language: pt, Expected: true},
+		{Name: "Scenario/Mars/Language/portuguese_mars.xml", Language: pt, Expected: true},
+		{Name: "Language/portuguese_brazil.xml", Language: pt},
+		{Name: "Language/portuguese_brazil_keys.xml", Language: pt},
+		{Name: "Language/portuguese_brazil.xml", Language: br, Expected: true},
+		{Name: "Language/portuguese_brazil_keys.xml", Language: br, Expected: true},
+		{Name: "Language/portuguese.xml", Language: br},
+		{Name: "Language/portuguese_tips.xml", Language: pt},
+		{Name: "Language/english.xml", Language: en},
+		{Name: "Language/portuguesex.xml", Language: pt},
+	} {
+		if got := isLanguageFile(tt.Name, tt.Language, hasEnglish); got != tt.Expected {
+			t.Errorf("%s of %s: %v", tt.Name, tt.Language.Name, got)
+		}
+	}
+}
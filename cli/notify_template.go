@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/spf13/viper"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+)
+
+const defaultAssetsTitle = "New assets"
+
+const defaultAssetsText = `The assets were automatically generated.
+
+{{if not .Folders -}}
+**No xml files changed.**
+{{else -}}
+**Files changed:**
+{{range .Folders -}}
+[{{.Name}}]: {{len .Files}}
+{{end -}}
+{{if .Languages}}
+**Languages affected:** {{join .Languages ", "}}
+{{end -}}
+{{if .Changelog}}
+**Changelog:**
+{{range .Changelog -}}
+{{.Language.Name}}: {{.Translated}} translated, {{.Revised}} revised, {{.Removed}} removed
+{{- with .Authors}} by {{join . ", "}}{{end}}
+{{end -}}
+{{end -}}
+{{end}}
+{{- with .Resources.URL}}
+See raw commit:
+{{.}}
+{{- end}}`
+
+// commitInfo describes commit for notification templates.
+type commitInfo struct {
+	Hash    string
+	Short   string // first 7 characters of hash
+	Message string
+	Author  string
+	When    time.Time
+	URL     string // blank if repository URL is not configured
+}
+
+func newCommitInfo(c *object.Commit, repoURL string) commitInfo {
+	hash := c.Hash.String()
+	info := commitInfo{
+		Hash:    hash,
+		Short:   hash[:7],
+		Message: c.Message,
+		Author:  c.Author.Name,
+		When:    c.Author.When,
+	}
+	if repoURL != "" {
+		info.URL = strings.TrimSuffix(repoURL, "/") + "/commit/" + info.Short
+	}
+	return info
+}
+
+// folderChange is list of changed xml files (without extension) in folder.
+type folderChange struct {
+	Name  string
+	Files []string
+}
+
+// assetsData is passed to "notify.assets" templates.
+type assetsData struct {
+	Version      string
+	Folders      []folderChange
+	Languages    []string // names of affected languages
+	Resources    commitInfo
+	Locales      commitInfo
+	LatestUpdate time.Time // latest non-automated locale commit
+	Changelog    []languageChangelog
+}
+
+// errorData is passed to "notify.error" templates.
+type errorData struct {
+	Title string
+	Text  string
+	Task  string
+}
+
+var templateFuncs = template.FuncMap{
+	"join":     strings.Join,
+	"humanize": humanize.Time,
+}
+
+// notification is configuration of notification kind, like "assets".
+//
+// The Title and Text are text/template sources.
+type notification struct {
+	Name    string
+	Channel string
+	Title   string
+	Text    string
+
+	// ArtifactURL is link to attached file, posted if file is too large
+	// to upload. Executed with .Version and .Name of file.
+	ArtifactURL string
+}
+
+func getNotification(name string) notification {
+	key := "notify." + name + "."
+	return notification{
+		Name:    name,
+		Channel: viper.GetString(key + "channel"),
+		Title:   viper.GetString(key + "title"),
+		Text:    viper.GetString(key + "text"),
+
+		ArtifactURL: viper.GetString(key + "artifact_url"),
+	}
+}
+
+func (n notification) execute(name, text string, data interface{}) (string, error) {
+	t, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse notify.%s template: %v", name, err)
+	}
+	b := new(strings.Builder)
+	if err = t.Execute(b, data); err != nil {
+		return "", fmt.Errorf("failed to execute notify.%s template: %v", name, err)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// render executes title and text templates with data.
+func (n notification) render(data interface{}) (title, text string, err error) {
+	if title, err = n.execute(n.Name+".title", n.Title, data); err != nil {
+		return "", "", err
+	}
+	if text, err = n.execute(n.Name+".text", n.Text, data); err != nil {
+		return "", "", err
+	}
+	return title, text, nil
+}
+
+// artifactURL returns link to file with provided name, or blank string if
+// not configured.
+func (n notification) artifactURL(version, name string) (string, error) {
+	if n.ArtifactURL == "" {
+		return "", nil
+	}
+	return n.execute(n.Name+".artifact_url", n.ArtifactURL, struct {
+		Version string
+		Name    string
+	}{
+		Version: version,
+		Name:    name,
+	})
+}
@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/st-10n/martian/assets"
+)
+
+// packageManifest is written to archive to trace the sources of assets.
+type packageManifest struct {
+	Version   string            `json:"version,omitempty"`
+	Resources string            `json:"resources,omitempty"` // commit hash
+	Locales   string            `json:"locales,omitempty"`   // commit hash
+	Files     map[string]string `json:"files"`               // name -> sha256
+}
+
+// readList reads file list written by bake.
+func readList(name string) ([]string, error) {
+	data, err := readFile(name)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		if l := strings.TrimSpace(s.Text()); l != "" {
+			names = append(names, l)
+		}
+	}
+	return names, s.Err()
+}
+
+var packageCmd = &cobra.Command{
+	Use: "package",
+	Aliases: []string{
+		"pkg", "p",
+	},
+	Short: "Pack the baked files to StreamingAssets.zip",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			f = cmd.Flags()
+
+			inDir, outName string
+			listName       string
+			fontsDir       string
+			fontsPath      string
+			localesDir     string
+			manifestName   string
+			files          []assets.File
+			err            error
+		)
+		if inDir, err = f.GetString("input"); err != nil {
+			return err
+		}
+		if outName, err = f.GetString("output"); err != nil {
+			return err
+		}
+		if len(outName) == 0 {
+			return errors.New("blank output file")
+		}
+		if listName, err = f.GetString("list"); err != nil {
+			return err
+		}
+		if fontsDir, err = f.GetString("fonts"); err != nil {
+			return err
+		}
+		if fontsPath, err = f.GetString("fonts-path"); err != nil {
+			return err
+		}
+		if localesDir, err = f.GetString("locales"); err != nil {
+			return err
+		}
+		if manifestName, err = f.GetString("manifest"); err != nil {
+			return err
+		}
+		names, err := readList(listName)
+		if err != nil {
+			return fmt.Errorf("failed to read list: %v", err)
+		}
+		add := func(fileName, name string) error {
+			data, err := readFile(fileName)
+			if err != nil {
+				return err
+			}
+			files = append(files, assets.File{
+				Name: path.Join(assets.Dir, name),
+				Data: data,
+			})
+			return nil
+		}
+		inAbs, err := filepath.Abs(inDir)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			nameAbs, err := filepath.Abs(name)
+			if err != nil {
+				return err
+			}
+			relative, err := filepath.Rel(inAbs, nameAbs)
+			if err != nil || strings.HasPrefix(relative, "..") {
+				return fmt.Errorf("file %s is not in %s", name, inDir)
+			}
+			if err = add(name, filepath.ToSlash(relative)); err != nil {
+				return err
+			}
+		}
+		if fontsDir != "" {
+			if err = filepath.Walk(fontsDir, func(name string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				relative, err := filepath.Rel(fontsDir, name)
+				if err != nil {
+					return err
+				}
+				return add(name, path.Join(fontsPath, filepath.ToSlash(relative)))
+			}); err != nil {
+				return fmt.Errorf("failed to add fonts: %v", err)
+			}
+		}
+		if manifestName != "" {
+			m := packageManifest{
+				Files: make(map[string]string),
+			}
+			for _, file := range files {
+				m.Files[strings.TrimPrefix(file.Name, assets.Dir+"/")] = hashData(file.Data)
+			}
+			if verRaw, err := readFile(filepath.Join(inDir, "version.txt")); err == nil {
+				m.Version = strings.TrimSpace(string(verRaw))
+			}
+			if h, err := headHash(inDir); err == nil {
+				m.Resources = h.String()
+			}
+			if h, err := headHash(localesDir); err == nil {
+				m.Locales = h.String()
+			}
+			data, err := json.MarshalIndent(m, "", "  ")
+			if err != nil {
+				return err
+			}
+			files = append(files, assets.File{
+				Name: path.Join(assets.Dir, manifestName),
+				Data: append(data, '\n'),
+			})
+		}
+		out, err := os.Create(outName)
+		if err != nil {
+			return err
+		}
+		if err = assets.WriteZip(out, files); err != nil {
+			out.Close()
+			return err
+		}
+		fmt.Println(outName, "files:", len(files))
+		return out.Close()
+	},
+}
+
+func init() {
+	{
+		f := packageCmd.Flags()
+		f.StringP("input", "i", ".", "input directory (StreamingAssets repo)")
+		f.StringP("output", "o", "StreamingAssets.zip", "output archive")
+		f.StringP("list", "l", "assets.txt", "file list written by bake")
+		f.String("fonts", "", "directory with fonts to include")
+		f.String("fonts-path", "Fonts", "path of fonts in archive (relative to StreamingAssets)")
+		f.String("locales", "locales", "locales repository for manifest")
+		f.String("manifest", "martian.json", "name of manifest with commits and file hashes (blank to skip)")
+	}
+	rootCmd.AddCommand(
+		packageCmd,
+	)
+}
@@ -5,6 +5,7 @@ package cli
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
@@ -29,6 +30,32 @@ func readFile(name string) ([]byte, error) {
 	return b, f.Close()
 }
 
+func writeFile(name string, data []byte) error {
+	f, err := os.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// createOutput creates output file, or returns stdout if name is "-".
+func createOutput(name string) (io.WriteCloser, error) {
+	if name == "-" || name == "" {
+		return nopWriteCloser{Writer: os.Stdout}, nil
+	}
+	return os.Create(name)
+}
+
 type Language struct {
 	Code   string `mapstructure:"code"`
 	Name   string `mapstructure:"name"`
@@ -46,8 +73,43 @@ func (l Language) GetPrefix() string {
 	)
 }
 
+// GetLocale returns locale directory name of language.
+func (l Language) GetLocale() string {
+	if l.Locale != "" {
+		return l.Locale
+	}
+	return strings.ToLower(l.Code)
+}
+
+// IsEnglish reports whether language is the original one.
+func (l Language) IsEnglish() bool {
+	return l.Code == "EN" || l.GetLocale() == "en"
+}
+
 type Languages []Language
 
+// selectLanguages returns configured languages, limited by names or codes
+// if limit is not blank.
+func selectLanguages(limit []string) (Languages, error) {
+	var languages, selected Languages
+	if err := viper.UnmarshalKey("languages", &languages); err != nil {
+		return nil, err
+	}
+	for _, lang := range languages {
+		if len(limit) == 0 {
+			selected = append(selected, lang)
+			continue
+		}
+		for _, limitLang := range limit {
+			if strings.EqualFold(limitLang, lang.Name) || strings.EqualFold(limitLang, lang.Code) {
+				selected = append(selected, lang)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "martian",
 	Short: "Stationeers Localization toolset",
@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/st-10n/martian/server"
+)
+
+// isLoopback reports whether listen address is bound to loopback
+// interface only, like "localhost:8080" or "127.0.0.1:8080".
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run HTTP server with pipeline webhooks and catalogs API",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			f = cmd.Flags()
+
+			addr, dir             string
+			localesDir, assetsDir string
+			insecure              bool
+			err                   error
+			cfg                   server.Config
+			languages             Languages
+		)
+		if addr, err = f.GetString("addr"); err != nil {
+			return err
+		}
+		if dir, err = f.GetString("dir"); err != nil {
+			return err
+		}
+		if err = viper.UnmarshalKey("serve", &cfg); err != nil {
+			return err
+		}
+		if insecure, err = f.GetBool("insecure"); err != nil {
+			return err
+		}
+		cfg.Secret = viper.GetString("serve.secret")
+		if cfg.Secret == "" {
+			if !insecure && !isLoopback(addr) {
+				return errors.New("no webhook secret provided (serve.secret or MARTIAN_WEBHOOK_SECRET), listen on localhost or use --insecure")
+			}
+			fmt.Println("warning: webhook signatures are not checked, no secret provided")
+		}
+		if len(cfg.Pipeline) == 0 {
+			fmt.Println("warning: no pipeline configured (serve.pipeline)")
+		}
+		if localesDir, err = f.GetString("locales"); err != nil {
+			return err
+		}
+		if assetsDir, err = f.GetString("assets"); err != nil {
+			return err
+		}
+		if err = viper.UnmarshalKey("languages", &languages); err != nil {
+			return err
+		}
+		cfg.Dir = dir
+		runner := server.ExecRunner{Dir: dir}
+		if name := viper.ConfigFileUsed(); name != "" {
+			if name, err = filepath.Abs(name); err != nil {
+				return err
+			}
+			runner.Args = []string{"--config", name}
+		}
+		s := server.New(cfg, runner)
+		if localesDir != "" {
+			api := &server.API{
+				Catalogs: &server.Catalogs{
+					Dir:      localesDir,
+					Interval: 5 * time.Second,
+				},
+				Assets: assetsDir,
+			}
+			for _, l := range languages {
+				api.Catalogs.Languages = append(api.Catalogs.Languages, server.Language{
+					Code:   l.Code,
+					Name:   l.Name,
+					Locale: l.GetLocale(),
+				})
+			}
+			api.Routes(s)
+		}
+		srv := &http.Server{
+			Addr:    addr,
+			Handler: s,
+		}
+		done := make(chan error, 1)
+		go func() {
+			sig := make(chan os.Signal, 1)
+			signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+			<-sig
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			done <- srv.Shutdown(ctx)
+		}()
+		fmt.Println("listening on", addr)
+		if err = srv.ListenAndServe(); err != http.ErrServerClosed {
+			return err
+		}
+		if err = <-done; err != nil {
+			return err
+		}
+		return s.Close()
+	},
+}
+
+func init() {
+	{
+		f := serveCmd.Flags()
+		f.String("addr", ":8080", "listen address")
+		f.StringP("dir", "d", ".", "working directory of pipeline")
+		f.String("locales", "locales", "locales directory served by API, blank to disable")
+		f.String("assets", "", "baked assets directory served by API (StreamingAssets)")
+		f.Bool("insecure", false, "allow unsigned webhooks on non-loopback address if no secret is provided")
+	}
+	rootCmd.AddCommand(
+		serveCmd,
+	)
+	viper.BindEnv("serve.secret", "MARTIAN_WEBHOOK_SECRET")
+}
@@ -0,0 +1,20 @@
+package cli
+
+import "testing"
+
+func TestIsLoopback(t *testing.T) {
+	for addr, expected := range map[string]bool{
+		"localhost:8080":   true,
+		"127.0.0.1:8080":   true,
+		"[::1]:8080":       true,
+		":8080":            false,
+		"0.0.0.0:8080":     false,
+		"192.168.1.2:8080": false,
+		"example.com:8080": false,
+		"localhost":        false,
+	} {
+		if got := isLoopback(addr); got != expected {
+			t.Errorf("%s: %v", addr, got)
+		}
+	}
+}
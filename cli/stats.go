@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"github.com/st-10n/martian/resource"
+)
+
+type languageStats struct {
+	Code     string                    `json:"code"`
+	Name     string                    `json:"name"`
+	Locale   string                    `json:"locale"`
+	Progress float64                   `json:"progress"`
+	Total    resource.Stats            `json:"total"`
+	Files    map[string]resource.Stats `json:"files"`
+}
+
+func (s languageStats) fileNames() []string {
+	names := make([]string, 0, len(s.Files))
+	for name := range s.Files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// readCatalogs reads all .po files from locale directory, trimming prefix
+// from file names.
+func readCatalogs(localeDir, prefix string) (resource.Entries, error) {
+	return resource.ReadCatalogs(os.DirFS(localeDir), prefix)
+}
+
+func writeStatsTable(w io.Writer, stats []languageStats) error {
+	t := tabwriter.NewWriter(w, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(t, "LANGUAGE\tFILE\tTOTAL\tTRANSLATED\tFUZZY\tUNTRANSLATED\tIDENTICAL\tWORDS\tPROGRESS\t")
+	row := func(lang, file string, s resource.Stats) {
+		fmt.Fprintf(t, "%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d/%d\t%.1f%%\t\n",
+			lang, file, s.Total, s.Translated, s.Fuzzy, s.Untranslated, s.Identical,
+			s.TranslatedWords, s.Words, s.Progress(),
+		)
+	}
+	for _, l := range stats {
+		for _, name := range l.fileNames() {
+			row(l.Name, name, l.Files[name])
+		}
+		row(l.Name, "*", l.Total)
+	}
+	return t.Flush()
+}
+
+func writeStatsMarkdown(w io.Writer, stats []languageStats) error {
+	const header = "| %s | Total | Translated | Fuzzy | Untranslated | Identical | Words | Progress |\n" +
+		"|:--|--:|--:|--:|--:|--:|--:|--:|\n"
+	row := func(name string, s resource.Stats) {
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d | %d | %d/%d | %.1f%% |\n",
+			name, s.Total, s.Translated, s.Fuzzy, s.Untranslated, s.Identical,
+			s.TranslatedWords, s.Words, s.Progress(),
+		)
+	}
+	fmt.Fprintln(w, "# Translation progress")
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, header, "Language")
+	for _, l := range stats {
+		row(fmt.Sprintf("%s (%s)", l.Name, l.Code), l.Total)
+	}
+	for _, l := range stats {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "## %s (%s)\n", l.Name, l.Code)
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, header, "File")
+		for _, name := range l.fileNames() {
+			row(name, l.Files[name])
+		}
+		_, err := fmt.Fprintf(w, "| **Total** | %d | %d | %d | %d | %d | %d/%d | %.1f%% |\n",
+			l.Total.Total, l.Total.Translated, l.Total.Fuzzy, l.Total.Untranslated, l.Total.Identical,
+			l.Total.TranslatedWords, l.Total.Words, l.Total.Progress(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var badgeTemplate = template.Must(template.New("badge").Parse(`<svg xmlns="http://www.w3.org/2000/svg" width="{{ .Width }}" height="20" role="img" aria-label="{{ .Label | html }}: {{ .Message }}">
+  <title>{{ .Label | html }}: {{ .Message }}</title>
+  <linearGradient id="s" x2="0" y2="100%">
+    <stop offset="0" stop-color="#bbb" stop-opacity=".1"/>
+    <stop offset="1" stop-opacity=".1"/>
+  </linearGradient>
+  <clipPath id="r">
+    <rect width="{{ .Width }}" height="20" rx="3" fill="#fff"/>
+  </clipPath>
+  <g clip-path="url(#r)">
+    <rect width="{{ .LabelWidth }}" height="20" fill="#555"/>
+    <rect x="{{ .LabelWidth }}" width="{{ .MessageWidth }}" height="20" fill="{{ .Color }}"/>
+    <rect width="{{ .Width }}" height="20" fill="url(#s)"/>
+  </g>
+  <g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">
+    <text x="{{ .LabelX }}" y="14">{{ .Label | html }}</text>
+    <text x="{{ .MessageX }}" y="14">{{ .Message }}</text>
+  </g>
+</svg>
+`))
+
+// badgeTextWidth approximates width of text in pixels for 11px Verdana.
+func badgeTextWidth(s string) int {
+	return len([]rune(s))*7 + 10
+}
+
+func badgeColor(progress float64) string {
+	switch {
+	case progress >= 90:
+		return "#4c1"
+	case progress >= 75:
+		return "#a4a61d"
+	case progress >= 50:
+		return "#dfb317"
+	case progress >= 25:
+		return "#fe7d37"
+	default:
+		return "#e05d44"
+	}
+}
+
+func writeStatsBadge(w io.Writer, s languageStats) error {
+	b := struct {
+		Label, Message, Color string
+
+		Width, LabelWidth, MessageWidth int
+		LabelX, MessageX                float64
+	}{
+		Label:   s.Name,
+		Message: fmt.Sprintf("%.0f%%", s.Progress),
+		Color:   badgeColor(s.Progress),
+	}
+	b.LabelWidth = badgeTextWidth(b.Label)
+	b.MessageWidth = badgeTextWidth(b.Message)
+	b.Width = b.LabelWidth + b.MessageWidth
+	b.LabelX = float64(b.LabelWidth) / 2
+	b.MessageX = float64(b.LabelWidth) + float64(b.MessageWidth)/2
+	return badgeTemplate.Execute(w, b)
+}
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Report translation progress of .po files",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var (
+			f = cmd.Flags()
+
+			inDir, outName string
+			format, prefix string
+			limit          []string
+			err            error
+			languages      Languages
+			stats          []languageStats
+		)
+		if inDir, err = f.GetString("input"); err != nil {
+			return err
+		}
+		if outName, err = f.GetString("output"); err != nil {
+			return err
+		}
+		if format, err = f.GetString("format"); err != nil {
+			return err
+		}
+		if prefix, err = f.GetString("prefix"); err != nil {
+			return err
+		}
+		if limit, err = f.GetStringSlice("limit"); err != nil {
+			return err
+		}
+		if languages, err = selectLanguages(limit); err != nil {
+			return err
+		}
+		for _, lang := range languages {
+			if lang.IsEnglish() {
+				continue
+			}
+			localeDir := filepath.Join(inDir, lang.GetLocale())
+			if _, statErr := os.Stat(localeDir); os.IsNotExist(statErr) {
+				continue
+			}
+			entries, err := readCatalogs(localeDir, prefix)
+			if err != nil {
+				return err
+			}
+			s := languageStats{
+				Code:   lang.Code,
+				Name:   lang.Name,
+				Locale: lang.GetLocale(),
+				Total:  entries.Stats(),
+				Files:  entries.StatsByFile(),
+			}
+			s.Progress = s.Total.Progress()
+			stats = append(stats, s)
+		}
+		if format == "svg" {
+			// Badge for every language.
+			if outName == "-" {
+				outName = "."
+			}
+			for _, s := range stats {
+				name := filepath.Join(outName, s.Locale+".svg")
+				out, err := os.Create(name)
+				if err != nil {
+					return err
+				}
+				if err = writeStatsBadge(out, s); err != nil {
+					out.Close()
+					return err
+				}
+				if err = out.Close(); err != nil {
+					return err
+				}
+				fmt.Println(name)
+			}
+			return nil
+		}
+		out, err := createOutput(outName)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+		switch format {
+		case "table":
+			err = writeStatsTable(out, stats)
+		case "json":
+			enc := json.NewEncoder(out)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(stats)
+		case "markdown", "md":
+			err = writeStatsMarkdown(out, stats)
+		default:
+			return fmt.Errorf("unknown format %q", format)
+		}
+		if err != nil {
+			return err
+		}
+		return out.Close()
+	},
+}
+
+func init() {
+	{
+		f := statsCmd.Flags()
+		f.StringP("input", "i", "locales", "input directory (locales)")
+		f.StringP("output", "o", "-", "output file, or directory for svg badges")
+		f.StringP("format", "f", "table", "output format (table, json, markdown, svg)")
+		f.StringSlice("limit", nil, "limit languages")
+		f.StringP("prefix", "p", "", "filename prefix")
+	}
+	rootCmd.AddCommand(
+		statsCmd,
+	)
+}
@@ -1,16 +1,80 @@
 package cli
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-	"strings"
+	"sort"
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	"github.com/st-10n/martian/assets"
+	"github.com/st-10n/martian/resource"
 )
 
+// sourceStamp describes the game version that source files were copied from.
+type sourceStamp struct {
+	Version string            `json:"version,omitempty"`
+	Files   map[string]string `json:"files"` // relative path -> sha256
+}
+
+func hashData(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+// readStamp reads stamp file with name that is relative to root of fsys,
+// or from file system if name is absolute.
+func readStamp(fsys fs.FS, name string) (*sourceStamp, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if filepath.IsAbs(name) {
+		data, err = readFile(name)
+	} else {
+		data, err = fs.ReadFile(fsys, filepath.ToSlash(name))
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &sourceStamp{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", name, err)
+	}
+	return s, nil
+}
+
+func writeStamp(name string, s *sourceStamp) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(name, append(data, '\n'))
+}
+
+// check returns names of files which content differs from stamp.
+func (s *sourceStamp) check(fsys fs.FS, names []string) ([]string, error) {
+	var changed []string
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		if s.Files[name] != hashData(data) {
+			changed = append(changed, name)
+		}
+	}
+	return changed, nil
+}
+
 var updateCmd = &cobra.Command{
 	Use: "update",
 	Aliases: []string{
@@ -22,10 +86,15 @@ var updateCmd = &cobra.Command{
 			f = cmd.Flags()
 
 			outDir, inDir string
-			templates     []originalFile
+			stampName     string
+			version       string
+			templates     []resource.Template
+			limit         []string
 			err           error
 			languages     Languages
 			english       Language
+			sync          bool
+			translations  bool
 		)
 		if inDir, err = f.GetString("input"); err != nil {
 			return err
@@ -40,6 +109,21 @@ var updateCmd = &cobra.Command{
 		if err = viper.UnmarshalKey("languages", &languages); err != nil {
 			return err
 		}
+		if stampName, err = f.GetString("stamp"); err != nil {
+			return err
+		}
+		if version, err = f.GetString("version"); err != nil {
+			return err
+		}
+		if sync, err = f.GetBool("sync"); err != nil {
+			return err
+		}
+		if translations, err = f.GetBool("translations"); err != nil {
+			return err
+		}
+		if limit, err = f.GetStringSlice("limit"); err != nil {
+			return err
+		}
 		for _, lang := range languages {
 			if lang.Code == "EN" {
 				english = lang
@@ -49,35 +133,76 @@ var updateCmd = &cobra.Command{
 		if english.Code == "" {
 			return errors.New("no english language configured (code=EN)")
 		}
-		if err = filepath.Walk(inDir, func(path string, info os.FileInfo, err error) error {
-			base := filepath.Base(path)
-			relative, err := filepath.Rel(inDir, filepath.Dir(path))
-			if err != nil {
-				return err
-			}
-			if strings.HasPrefix(base, "english") && strings.HasSuffix(base, ".xml") {
-				templates = append(templates, originalFile{
-					Postfix: strings.TrimPrefix(base, "english"),
-					Path:    relative,
-				})
-			}
-			return nil
-		}); err != nil {
-			return fmt.Errorf("failed to walk %d: %v", inDir, err)
+		input, inputCloser, err := assets.Open(inDir)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %v", inDir, err)
+		}
+		defer inputCloser.Close()
+		if templates, err = resource.FindTemplates(input); err != nil {
+			return fmt.Errorf("failed to walk %s: %v", inDir, err)
 		}
 		if len(templates) == 0 {
 			return errors.New("no english files found in output folder")
 		}
 		fmt.Println("templates:", templates)
+		previous := &sourceStamp{}
+		if stampName != "" {
+			if previous, err = readStamp(os.DirFS(outDir), stampName); errors.Is(err, fs.ErrNotExist) {
+				previous = &sourceStamp{}
+			} else if err != nil {
+				return err
+			}
+			if !filepath.IsAbs(stampName) {
+				stampName = filepath.Join(outDir, stampName)
+			}
+		}
+		// List of copied files, slash-separated and relative to
+		// input and output directories.
+		var names []string
 		for _, t := range templates {
-			name := "english" + t.Postfix
-			origName := filepath.Join(inDir, t.Path, name)
-			orig, err := readFile(origName)
+			names = append(names, t.String())
+		}
+		if translations {
+			if languages, err = selectLanguages(limit); err != nil {
+				return err
+			}
+			for _, t := range templates {
+				for _, lang := range languages {
+					if lang.IsEnglish() {
+						continue
+					}
+					name := path.Join(t.Path, t.Name(lang.GetPrefix()))
+					if _, statErr := fs.Stat(input, name); statErr == nil {
+						names = append(names, name)
+					}
+				}
+			}
+		}
+		stamp := &sourceStamp{
+			Version: version,
+			Files:   make(map[string]string),
+		}
+		if stamp.Version == "" {
+			stamp.Version = previous.Version
+		}
+		var added, changed, removed []string
+		for _, name := range names {
+			orig, err := fs.ReadFile(input, name)
 			if err != nil {
-				return fmt.Errorf("failed to read orig file %s: %v", origName, err)
+				return fmt.Errorf("failed to read orig file %s: %v", name, err)
+			}
+			outName := filepath.Join(outDir, filepath.FromSlash(name))
+			current, err := readFile(outName)
+			switch {
+			case os.IsNotExist(err):
+				added = append(added, name)
+			case err != nil:
+				return err
+			case !bytes.Equal(current, orig):
+				changed = append(changed, name)
 			}
-			_ = os.MkdirAll(filepath.Join(outDir, t.Path), 0777)
-			outName := filepath.Join(outDir, t.Path, name)
+			stamp.Files[name] = hashData(orig)
+			_ = os.MkdirAll(filepath.Dir(outName), 0777)
 			outF, err := os.Create(outName)
 			if err != nil {
 				return fmt.Errorf("failed to create out file: %v", err)
@@ -88,8 +213,65 @@ var updateCmd = &cobra.Command{
 			if err = outF.Close(); err != nil {
 				return err
 			}
-			fmt.Println(origName, "->", outName)
+			fmt.Println(name, "->", outName)
+		}
+		if sync {
+			// Removing previously copied or english files that are
+			// not present in game anymore.
+			// Files that still exist in game are kept, even if they
+			// are not copied now (e.g. translations without the flag).
+			candidates := make(map[string]bool)
+			for name := range previous.Files {
+				candidates[name] = true
+			}
+			outTemplates, err := resource.FindTemplates(os.DirFS(outDir))
+			if err != nil {
+				return err
+			}
+			for _, t := range outTemplates {
+				candidates[t.String()] = true
+			}
+			for name := range candidates {
+				if _, ok := stamp.Files[name]; ok {
+					continue
+				}
+				if _, statErr := fs.Stat(input, name); statErr == nil {
+					continue
+				}
+				if err = os.Remove(filepath.Join(outDir, filepath.FromSlash(name))); os.IsNotExist(err) {
+					continue
+				} else if err != nil {
+					return err
+				}
+				removed = append(removed, name)
+			}
+		}
+		if stampName != "" {
+			if err = writeStamp(stampName, stamp); err != nil {
+				return err
+			}
+		}
+		if version != "" {
+			if err = writeFile(filepath.Join(outDir, "version.txt"), []byte(version+"\n")); err != nil {
+				return err
+			}
+		}
+		sort.Strings(removed)
+		fmt.Println("version:", stamp.Version)
+		for _, s := range []struct {
+			title string
+			names []string
+		}{
+			{"added", added},
+			{"changed", changed},
+			{"removed", removed},
+		} {
+			fmt.Printf("%s: %d\n", s.title, len(s.names))
+			for _, name := range s.names {
+				fmt.Printf("  %s\n", name)
+			}
 		}
+		fmt.Printf("unchanged: %d\n", len(names)-len(added)-len(changed))
 		return nil
 	},
 }
@@ -98,7 +280,12 @@ func init() {
 	{
 		f := updateCmd.Flags()
 		f.StringP("output", "o", ".", "output directory (StreamingAssets repo)")
-		f.StringP("input", "i", "game", "input directory (StreamingAssets from game)")
+		f.StringP("input", "i", "game", "input directory or archive (StreamingAssets from game)")
+		f.Bool("sync", false, "remove files that are not present in game")
+		f.Bool("translations", false, "import official translations of configured languages")
+		f.StringSlice("limit", nil, "limit languages of imported translations")
+		f.String("version", "", "game version (build number) to write to version.txt and stamp")
+		f.String("stamp", "sources.json", "version stamp file with hashes of copied files (relative to output if not absolute)")
 	}
 	rootCmd.AddCommand(
 		updateCmd,
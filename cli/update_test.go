@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadStamp(t *testing.T) {
+	stamp := &sourceStamp{
+		Version: "0.2.1234",
+		Files:   map[string]string{"Language/english.xml": hashData([]byte("<Language/>"))},
+	}
+	abs := filepath.Join(t.TempDir(), "sources.json")
+	if err := writeStamp(abs, stamp); err != nil {
+		t.Fatal(err)
+	}
+	data, err := readFile(abs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := fstest.MapFS{
+		"stamps/sources.json": &fstest.MapFile{Data: data},
+	}
+	for _, name := range []string{
+		"stamps/sources.json",
+		filepath.Join("stamps", "sources.json"),
+		abs,
+	} {
+		s, err := readStamp(input, name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if s.Version != stamp.Version || s.Files["Language/english.xml"] != stamp.Files["Language/english.xml"] {
+			t.Errorf("%s: unexpected stamp %+v", name, s)
+		}
+	}
+	for _, name := range []string{
+		"sources.json",
+		filepath.Join(filepath.Dir(abs), "missing.json"),
+	} {
+		if _, err := readStamp(input, name); !errors.Is(err, fs.ErrNotExist) {
+			t.Errorf("%s: unexpected error %v", name, err)
+		}
+	}
+}
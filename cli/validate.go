@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/st-10n/martian/resource"
+)
+
+// schemas finds schemas of language files.
+type schemas struct {
+	configured *resource.Schema
+	inferred   map[string]*resource.Schema // by name of english file
+}
+
+// english returns name of english file in the same directory with the
+// longest postfix of name, like "english_keys.xml" for
+// "russian_keys.xml", or blank string.
+func (s *schemas) english(name string) (string, error) {
+	files, err := ioutil.ReadDir(filepath.Dir(name))
+	if err != nil {
+		return "", err
+	}
+	var found, postfix string
+	for _, f := range files {
+		if !strings.HasPrefix(f.Name(), "english") || !strings.HasSuffix(f.Name(), ".xml") {
+			continue
+		}
+		p := strings.TrimPrefix(f.Name(), "english")
+		if strings.HasSuffix(filepath.Base(name), p) && len(p) > len(postfix) {
+			found, postfix = filepath.Join(filepath.Dir(name), f.Name()), p
+		}
+	}
+	return found, nil
+}
+
+// get returns schema inferred from english file for name, or configured
+// one if there is no english file.
+func (s *schemas) get(name string) (*resource.Schema, error) {
+	englishName, err := s.english(name)
+	if err != nil {
+		return nil, err
+	}
+	if englishName == "" {
+		if s.configured == nil {
+			return nil, fmt.Errorf("no english file or configured schema for %s", name)
+		}
+		return s.configured, nil
+	}
+	if schema, ok := s.inferred[englishName]; ok {
+		return schema, nil
+	}
+	data, err := readFile(englishName)
+	if err != nil {
+		return nil, err
+	}
+	schema, err := resource.InferSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to infer schema of %s: %v", englishName, err)
+	}
+	s.inferred[englishName] = schema
+	return schema, nil
+}
+
+// getSchemas returns schemas with configured "schema".
+func getSchemas() (*schemas, error) {
+	s := &schemas{
+		inferred: make(map[string]*resource.Schema),
+	}
+	if viper.IsSet("schema") {
+		s.configured = &resource.Schema{}
+		if err := viper.UnmarshalKey("schema", s.configured); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// printValidationErrors prints errors of file and returns count of them.
+func printValidationErrors(name string, errs []resource.ValidationError) int {
+	for _, e := range errs {
+		fmt.Printf("%s: %s\n", name, e)
+	}
+	return len(errs)
+}
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [files or directories]",
+	Short: "Validate structure of language xml files",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 {
+			return errors.New("no files provided")
+		}
+		s, err := getSchemas()
+		if err != nil {
+			return err
+		}
+		var names []string
+		for _, arg := range args {
+			if err = filepath.Walk(arg, func(name string, info os.FileInfo, err error) error {
+				if err != nil {
+					return err
+				}
+				base := filepath.Base(name)
+				if info.IsDir() || !strings.HasSuffix(base, ".xml") {
+					return nil
+				}
+				if name != arg && strings.HasPrefix(base, "english") {
+					// English files are schemas.
+					return nil
+				}
+				names = append(names, name)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		var count int
+		for _, name := range names {
+			schema, err := s.get(name)
+			if err != nil {
+				return err
+			}
+			data, err := readFile(name)
+			if err != nil {
+				return err
+			}
+			count += printValidationErrors(name, resource.Validate(data, schema))
+		}
+		if count > 0 {
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%d errors found", count)
+		}
+		fmt.Printf("%d files are valid\n", len(names))
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(
+		validateCmd,
+	)
+}
@@ -0,0 +1,135 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	discord "github.com/bwmarrin/discordgo"
+)
+
+// Discord limits.
+const (
+	// DiscordMaxFileSize is default upload limit.
+	DiscordMaxFileSize = 8 << 20
+	// discordDescriptionLimit is maximum embed description length.
+	discordDescriptionLimit = 2048
+)
+
+// Discord sends messages as embeds to Discord channel.
+//
+// Long descriptions are split across multiple messages, and files larger
+// than MaxFileSize are posted as links if they have URL.
+type Discord struct {
+	Session     *discord.Session
+	Channel     string
+	MaxFileSize int // DiscordMaxFileSize if zero
+	Retry       Retry
+}
+
+// NewDiscord creates Discord notifier with bot token.
+func NewDiscord(token, channel string) (*Discord, error) {
+	if len(token) == 0 {
+		return nil, errors.New("no discord token provided")
+	}
+	s, err := discord.New("Bot " + token)
+	if err != nil {
+		return nil, err
+	}
+	return &Discord{
+		Session: s,
+		Channel: channel,
+	}, nil
+}
+
+// attachments returns files that can be uploaded and fields with links
+// to the rest.
+func (d *Discord) attachments(files []File) ([]File, []Field, error) {
+	maxSize := d.MaxFileSize
+	if maxSize == 0 {
+		maxSize = DiscordMaxFileSize
+	}
+	var (
+		attached []File
+		links    []Field
+		total    int
+	)
+	for _, f := range files {
+		if total+len(f.Data) <= maxSize {
+			total += len(f.Data)
+			attached = append(attached, f)
+			continue
+		}
+		if f.URL == "" {
+			return nil, nil, fmt.Errorf("file %s is too large (%d bytes) and has no url", f.Name, len(f.Data))
+		}
+		links = append(links, Field{
+			Name:  f.Name,
+			Value: f.URL,
+		})
+	}
+	return attached, links, nil
+}
+
+// Notify sends message to channel.
+func (d *Discord) Notify(ctx context.Context, m Message) error {
+	channel := m.Channel
+	if channel == "" {
+		channel = d.Channel
+	}
+	if channel == "" {
+		return errors.New("no discord channel provided")
+	}
+	files, links, err := d.attachments(m.Files)
+	if err != nil {
+		return fmt.Errorf("discord: %v", err)
+	}
+	parts := Split(m.Description, discordDescriptionLimit)
+	for i, part := range parts {
+		embed := &discord.MessageEmbed{
+			Color:       m.Color,
+			Description: part,
+		}
+		send := &discord.MessageSend{
+			Embed: embed,
+		}
+		if i == 0 {
+			embed.Title = m.Title
+			send.Content = m.Mention
+		}
+		if i == len(parts)-1 {
+			if m.Footer != "" {
+				embed.Footer = &discord.MessageEmbedFooter{
+					Text: m.Footer,
+				}
+			}
+			// Copying fields, so links are not written to backing array of
+			// caller.
+			for _, f := range append(append([]Field(nil), m.Fields...), links...) {
+				embed.Fields = append(embed.Fields, &discord.MessageEmbedField{
+					Name:  f.Name,
+					Value: f.Value,
+				})
+			}
+		}
+		if err = d.Retry.Do(ctx, func() error {
+			send.Files = nil
+			if i == len(parts)-1 {
+				for _, f := range files {
+					// Readers should be re-created on retry.
+					send.Files = append(send.Files, &discord.File{
+						Name:        f.Name,
+						ContentType: f.ContentType,
+						Reader:      bytes.NewReader(f.Data),
+					})
+				}
+			}
+			_, err := d.Session.ChannelMessageSendComplex(channel, send)
+			return err
+		}); err != nil {
+			return fmt.Errorf("discord: %v", err)
+		}
+	}
+	return nil
+}
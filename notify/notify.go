@@ -0,0 +1,175 @@
+// Package notify implements notification backends, like Discord, webhooks
+// or email.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Common colors of messages.
+const (
+	ColorGreen = 0x228B22
+	ColorRed   = 0xff2400
+)
+
+// Field is named value of message.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// File is attached to message.
+type File struct {
+	Name        string
+	ContentType string
+	Data        []byte
+
+	// URL is link to file that is posted instead if backend can't
+	// upload it, like file larger than Discord upload limit.
+	URL string
+}
+
+// Message is notification that can be delivered by any Notifier.
+//
+// Description is markdown-formatted, like in Discord.
+type Message struct {
+	Title       string
+	Description string
+	Color       int
+	Fields      []Field
+	Footer      string
+	Files       []File
+
+	// Mention is sent as plain text before message, so mentioned users
+	// are notified, like "<@&role>" for Discord.
+	Mention string
+
+	// Channel is backend-specific destination, like Discord channel ID.
+	// If blank, the configured one is used.
+	Channel string
+}
+
+// Text returns plain text representation of message.
+func (m Message) Text() string {
+	b := new(strings.Builder)
+	if m.Title != "" {
+		fmt.Fprintf(b, "%s\n\n", m.Title)
+	}
+	if m.Description != "" {
+		fmt.Fprintf(b, "%s\n", strings.TrimSpace(m.Description))
+	}
+	if len(m.Fields) > 0 {
+		b.WriteString("\n")
+	}
+	for _, f := range m.Fields {
+		fmt.Fprintf(b, "%s: %s\n", f.Name, f.Value)
+	}
+	for _, f := range m.Files {
+		fmt.Fprintf(b, "Attached: %s\n", fileName(f))
+	}
+	if m.Footer != "" {
+		fmt.Fprintf(b, "\n%s\n", m.Footer)
+	}
+	return b.String()
+}
+
+// fileName returns file name with url, if any.
+func fileName(f File) string {
+	if f.URL == "" {
+		return f.Name
+	}
+	return f.Name + " (" + f.URL + ")"
+}
+
+// Notifier delivers messages.
+type Notifier interface {
+	Notify(ctx context.Context, m Message) error
+}
+
+// Multi delivers messages with all notifiers.
+type Multi []Notifier
+
+// Notify calls all notifiers, returning first error.
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var firstErr error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Config of notifier.
+type Config struct {
+	Type string `mapstructure:"type"` // discord, webhook, slack, matrix or smtp
+
+	// Retries of temporary errors, DefaultRetry is used if zero.
+	Retries int           `mapstructure:"retries"`
+	Backoff time.Duration `mapstructure:"backoff"`
+
+	// Discord.
+	Token   string `mapstructure:"token"`
+	Channel string `mapstructure:"channel"`
+
+	// Webhooks.
+	URL string `mapstructure:"url"`
+
+	// SMTP.
+	Addr     string   `mapstructure:"addr"` // host:port
+	From     string   `mapstructure:"from"`
+	To       []string `mapstructure:"to"`
+	Username string   `mapstructure:"username"`
+	Password string   `mapstructure:"password"`
+}
+
+func (c Config) retry() Retry {
+	r := DefaultRetry
+	if c.Retries > 0 {
+		r.Attempts = c.Retries + 1
+	}
+	if c.Backoff > 0 {
+		r.Backoff = c.Backoff
+	}
+	return r
+}
+
+// New creates notifier from config.
+func New(c Config) (Notifier, error) {
+	switch c.Type {
+	case "discord":
+		d, err := NewDiscord(c.Token, c.Channel)
+		if err != nil {
+			return nil, err
+		}
+		d.Retry = c.retry()
+		return d, nil
+	case "webhook", "slack", "matrix":
+		if c.URL == "" {
+			return nil, fmt.Errorf("no url provided for %s", c.Type)
+		}
+		return &Webhook{
+			URL:    c.URL,
+			Format: c.Type,
+			Client: http.DefaultClient,
+			Retry:  c.retry(),
+		}, nil
+	case "smtp":
+		if c.Addr == "" || c.From == "" || len(c.To) == 0 {
+			return nil, fmt.Errorf("smtp: addr, from and to should be provided")
+		}
+		return &SMTP{
+			Addr:     c.Addr,
+			From:     c.From,
+			To:       c.To,
+			Username: c.Username,
+			Password: c.Password,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown notifier type %q", c.Type)
+	}
+}
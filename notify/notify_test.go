@@ -0,0 +1,434 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	discord "github.com/bwmarrin/discordgo"
+)
+
+var testMessage = Message{
+	Title:       "New assets",
+	Description: "The assets were automatically generated.\n\n**No xml files changed.**",
+	Color:       ColorGreen,
+	Fields: []Field{
+		{Name: "Version", Value: "v0.2"},
+	},
+	Footer: "Generated by Martian",
+	Files: []File{
+		{Name: "StreamingAssets.zip", ContentType: "application/zip", Data: []byte("zip")},
+	},
+}
+
+func TestWebhook(t *testing.T) {
+	for _, format := range []string{"webhook", "slack", "matrix"} {
+		t.Run(format, func(t *testing.T) {
+			var got map[string]interface{}
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Content-Type") != "application/json" {
+					t.Error("unexpected content type")
+				}
+				if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer s.Close()
+			n, err := New(Config{Type: format, URL: s.URL})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err = n.Notify(context.Background(), testMessage); err != nil {
+				t.Fatal(err)
+			}
+			switch format {
+			case "webhook":
+				if got["title"] != testMessage.Title {
+					t.Errorf("unexpected payload %v", got)
+				}
+			case "slack":
+				if got["text"] != "*New assets*" || len(got["attachments"].([]interface{})) != 1 {
+					t.Errorf("unexpected payload %v", got)
+				}
+			case "matrix":
+				if !strings.Contains(got["html"].(string), "<b>No xml files changed.</b>") {
+					t.Errorf("unexpected payload %v", got)
+				}
+			}
+		})
+	}
+	t.Run("Error", func(t *testing.T) {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "bad request", http.StatusBadRequest)
+		}))
+		defer s.Close()
+		n := &Webhook{URL: s.URL, Format: "webhook"}
+		if err := n.Notify(context.Background(), testMessage); err == nil {
+			t.Error("should fail")
+		}
+	})
+}
+
+func TestWebhookMention(t *testing.T) {
+	m := testMessage
+	m.Mention = "@russian"
+	n := &Webhook{Format: "slack"}
+	if got := n.payload(m).(slackMessage).Text; got != "@russian *New assets*" {
+		t.Errorf("unexpected slack text %q", got)
+	}
+	n.Format = "matrix"
+	if got := n.payload(m).(matrixMessage).HTML; !strings.HasPrefix(got, "@russian<br>") {
+		t.Errorf("unexpected matrix html %q", got)
+	}
+}
+
+// rewriteTransport sends all requests to test server.
+type rewriteTransport struct {
+	target *url.URL
+}
+
+func (r rewriteTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req.URL.Scheme = r.target.Scheme
+	req.URL.Host = r.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestDiscord(t *testing.T) {
+	var (
+		path string
+		body string
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	defer s.Close()
+	target, _ := url.Parse(s.URL)
+	d, err := NewDiscord("token", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Session.Client = &http.Client{Transport: rewriteTransport{target: target}}
+	if err = d.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(path, "/channels/42/messages") {
+		t.Errorf("unexpected path %s", path)
+	}
+	for _, s := range []string{"StreamingAssets.zip", "New assets", "Generated by Martian"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("%q not found in request", s)
+		}
+	}
+}
+
+// smtpServer is minimal SMTP server that accepts one message.
+type smtpServer struct {
+	ln   net.Listener
+	wg   sync.WaitGroup
+	data string
+	rcpt []string
+}
+
+func (s *smtpServer) serve(t *testing.T) {
+	defer s.wg.Done()
+	conn, err := s.ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(l string) { conn.Write([]byte(l + "\r\n")) }
+	reply("220 localhost ESMTP")
+	for {
+		l, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.ToUpper(strings.TrimSpace(l))
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.rcpt = append(s.rcpt, strings.TrimSpace(l[len("RCPT TO:"):]))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 Go ahead")
+			b := new(strings.Builder)
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				b.WriteString(l)
+			}
+			s.data = b.String()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestSMTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &smtpServer{ln: ln}
+	s.wg.Add(1)
+	go s.serve(t)
+	n, err := New(Config{
+		Type: "smtp",
+		Addr: ln.Addr().String(),
+		From: "martian@example.com",
+		To:   []string{"team@example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = n.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+	ln.Close()
+	s.wg.Wait()
+	if len(s.rcpt) != 1 || s.rcpt[0] != "<team@example.com>" {
+		t.Errorf("unexpected recipients %v", s.rcpt)
+	}
+	for _, str := range []string{
+		"Subject: New assets",
+		`filename=StreamingAssets.zip`,
+		"multipart/mixed",
+	} {
+		if !strings.Contains(s.data, str) {
+			t.Errorf("%q not found in message", str)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	for _, tt := range []struct {
+		Text  string
+		Limit int
+		Parts []string
+	}{
+		{Text: "", Limit: 10, Parts: []string{""}},
+		{Text: "short", Limit: 10, Parts: []string{"short"}},
+		{Text: "first\nsecond\nthird", Limit: 14, Parts: []string{"first\nsecond", "third"}},
+		{Text: "Привет", Limit: 5, Parts: []string{"Пр", "ив", "ет"}},
+		{Text: "Привет", Limit: 1, Parts: []string{"П", "р", "и", "в", "е", "т"}},
+	} {
+		got := Split(tt.Text, tt.Limit)
+		if len(got) != len(tt.Parts) {
+			t.Errorf("Split(%q, %d) = %q, expected %q", tt.Text, tt.Limit, got, tt.Parts)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.Parts[i] {
+				t.Errorf("Split(%q, %d) = %q, expected %q", tt.Text, tt.Limit, got, tt.Parts)
+				break
+			}
+		}
+	}
+}
+
+var testRetry = Retry{
+	Attempts: 3,
+	Backoff:  time.Millisecond,
+}
+
+func TestWebhookRetry(t *testing.T) {
+	for _, tt := range []struct {
+		Name     string
+		Statuses []int
+		Requests int
+		Fail     bool
+	}{
+		{Name: "ServerError", Statuses: []int{503, 502, 200}, Requests: 3},
+		{Name: "RateLimit", Statuses: []int{429, 200}, Requests: 2},
+		{Name: "Exhausted", Statuses: []int{500, 500, 500, 200}, Requests: 3, Fail: true},
+		{Name: "BadRequest", Statuses: []int{400, 200}, Requests: 1, Fail: true},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			var requests int
+			s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				code := tt.Statuses[requests]
+				requests++
+				if code == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "0")
+				}
+				w.WriteHeader(code)
+			}))
+			defer s.Close()
+			n := &Webhook{URL: s.URL, Format: "webhook", Retry: testRetry}
+			err := n.Notify(context.Background(), testMessage)
+			if (err != nil) != tt.Fail {
+				t.Errorf("unexpected error %v", err)
+			}
+			if requests != tt.Requests {
+				t.Errorf("%d requests, expected %d", requests, tt.Requests)
+			}
+		})
+	}
+}
+
+// discordMessage is message received by Discord stand-in.
+type discordMessage struct {
+	Content string
+	Embed   discord.MessageEmbed
+	Files   []string
+}
+
+// discordStandIn returns Discord notifier that sends requests to local
+// server, recording received messages. The first failures requests are
+// answered with status 500.
+func discordStandIn(t *testing.T, failures int) (*Discord, *[]discordMessage, func()) {
+	var (
+		messages []discordMessage
+		requests int
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests <= failures {
+			http.Error(w, `{"message": "internal"}`, http.StatusInternalServerError)
+			return
+		}
+		var (
+			m       discordMessage
+			payload []byte
+		)
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/") {
+			mr, err := r.MultipartReader()
+			if err != nil {
+				t.Fatal(err)
+			}
+			for {
+				p, err := mr.NextPart()
+				if err != nil {
+					break
+				}
+				if p.FormName() == "payload_json" {
+					payload, _ = ioutil.ReadAll(p)
+				} else {
+					m.Files = append(m.Files, p.FileName())
+				}
+			}
+		} else {
+			payload, _ = ioutil.ReadAll(r.Body)
+		}
+		if err := json.Unmarshal(payload, &m); err != nil {
+			t.Error(err)
+		}
+		messages = append(messages, m)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": "1"}`))
+	}))
+	target, _ := url.Parse(s.URL)
+	d, err := NewDiscord("token", "42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Session.Client = &http.Client{Transport: rewriteTransport{target: target}}
+	d.Retry = testRetry
+	return d, &messages, s.Close
+}
+
+func TestDiscordSplit(t *testing.T) {
+	d, messages, done := discordStandIn(t, 0)
+	defer done()
+	m := testMessage
+	m.Mention = "@here"
+	m.Description = strings.Repeat("Строка перевода\n", 150)
+	if err := d.Notify(context.Background(), m); err != nil {
+		t.Fatal(err)
+	}
+	if len(*messages) != 3 {
+		t.Fatalf("%d messages, expected 3", len(*messages))
+	}
+	var description string
+	for i, got := range *messages {
+		if !utf8.ValidString(got.Embed.Description) || len(got.Embed.Description) > discordDescriptionLimit {
+			t.Errorf("bad description of message %d", i)
+		}
+		description += got.Embed.Description + "\n"
+		last := i == len(*messages)-1
+		if (got.Embed.Title != "") != (i == 0) || (got.Content != "") != (i == 0) {
+			t.Errorf("title and mention should be only in first message")
+		}
+		if (len(got.Files) > 0) != last || (len(got.Embed.Fields) > 0) != last {
+			t.Errorf("files and fields should be only in last message")
+		}
+	}
+	if strings.TrimSpace(description) != strings.TrimSpace(m.Description) {
+		t.Error("description mismatch")
+	}
+}
+
+func TestDiscordLargeFile(t *testing.T) {
+	large := File{
+		Name: "StreamingAssets.zip",
+		Data: make([]byte, 100),
+	}
+	t.Run("URL", func(t *testing.T) {
+		d, messages, done := discordStandIn(t, 0)
+		defer done()
+		d.MaxFileSize = 10
+		m := testMessage
+		m.Files = []File{large}
+		m.Files[0].URL = "https://example.com/StreamingAssets.zip"
+		if err := d.Notify(context.Background(), m); err != nil {
+			t.Fatal(err)
+		}
+		got := (*messages)[0]
+		if len(got.Files) != 0 {
+			t.Error("file should not be uploaded")
+		}
+		link := got.Embed.Fields[len(got.Embed.Fields)-1]
+		if link.Name != large.Name || link.Value != m.Files[0].URL {
+			t.Errorf("unexpected link %+v", link)
+		}
+	})
+	t.Run("NoURL", func(t *testing.T) {
+		d, messages, done := discordStandIn(t, 0)
+		defer done()
+		d.MaxFileSize = 10
+		m := testMessage
+		m.Files = []File{large}
+		if err := d.Notify(context.Background(), m); err == nil {
+			t.Error("should fail")
+		}
+		if len(*messages) != 0 {
+			t.Error("nothing should be sent")
+		}
+	})
+}
+
+func TestDiscordRetry(t *testing.T) {
+	d, messages, done := discordStandIn(t, 2)
+	defer done()
+	if err := d.Notify(context.Background(), testMessage); err != nil {
+		t.Fatal(err)
+	}
+	if len(*messages) != 1 || len((*messages)[0].Files) != 1 {
+		t.Errorf("unexpected messages %+v", *messages)
+	}
+}
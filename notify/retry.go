@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	discord "github.com/bwmarrin/discordgo"
+)
+
+// Retry configures retries of failed requests.
+//
+// Only temporary errors are retried, like rate limits, server (5xx) and
+// network errors.
+type Retry struct {
+	Attempts int           // total attempts, zero means DefaultRetry
+	Backoff  time.Duration // delay before first retry, doubled for each next one
+}
+
+// DefaultRetry is used for zero Retry.
+var DefaultRetry = Retry{
+	Attempts: 4,
+	Backoff:  time.Second,
+}
+
+// StatusError is returned on unexpected HTTP response status.
+type StatusError struct {
+	Code       int
+	Status     string
+	Body       string
+	RetryAfter time.Duration // from Retry-After header
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status %s", e.Status)
+	}
+	return fmt.Sprintf("unexpected status %s: %s", e.Status, e.Body)
+}
+
+func temporaryStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+// Temporary reports whether request can be retried.
+func (e *StatusError) Temporary() bool {
+	return temporaryStatus(e.Code)
+}
+
+func temporary(err error) bool {
+	var (
+		statusErr *StatusError
+		restErr   *discord.RESTError
+		netErr    net.Error
+	)
+	switch {
+	case errors.As(err, &statusErr):
+		return statusErr.Temporary()
+	case errors.As(err, &restErr):
+		return restErr.Response != nil && temporaryStatus(restErr.Response.StatusCode)
+	case errors.As(err, &netErr):
+		return true
+	default:
+		return false
+	}
+}
+
+// Do calls f until it succeeds, fails with non-temporary error, attempts
+// are exhausted or ctx is done.
+func (r Retry) Do(ctx context.Context, f func() error) error {
+	if r.Attempts == 0 {
+		r = DefaultRetry
+	}
+	backoff := r.Backoff
+	for attempt := 1; ; attempt++ {
+		err := f()
+		if err == nil || attempt >= r.Attempts || !temporary(err) {
+			return err
+		}
+		wait := backoff
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > wait {
+			wait = statusErr.RetryAfter
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+		backoff *= 2
+	}
+}
@@ -0,0 +1,100 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+)
+
+// SMTP sends messages by email.
+type SMTP struct {
+	Addr     string // host:port
+	From     string
+	To       []string
+	Username string // optional, PLAIN auth is used if set
+	Password string
+}
+
+// writeBase64 writes base64-encoded data wrapped to 76 columns.
+func writeBase64(b *bytes.Buffer, data []byte) {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded + "\r\n")
+}
+
+// body returns MIME message with plain text and attached files.
+func (s *SMTP) body(m Message) ([]byte, error) {
+	b := new(bytes.Buffer)
+	mw := multipart.NewWriter(b)
+	fmt.Fprintf(b, "From: %s\r\n", s.From)
+	fmt.Fprintf(b, "To: %s\r\n", strings.Join(s.To, ", "))
+	fmt.Fprintf(b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Title))
+	fmt.Fprint(b, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(b, "Content-Type: multipart/mixed; boundary=%s\r\n\r\n", mw.Boundary())
+	text, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {"text/plain; charset=utf-8"},
+		"Content-Transfer-Encoding": {"base64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	textBuf := new(bytes.Buffer)
+	writeBase64(textBuf, []byte(m.Text()))
+	if _, err = text.Write(textBuf.Bytes()); err != nil {
+		return nil, err
+	}
+	for _, f := range m.Files {
+		contentType := f.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": f.Name})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		fileBuf := new(bytes.Buffer)
+		writeBase64(fileBuf, f.Data)
+		if _, err = part.Write(fileBuf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	if err = mw.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Notify sends message by email. The net/smtp does not support context,
+// so ctx is ignored.
+func (s *SMTP) Notify(ctx context.Context, m Message) error {
+	body, err := s.body(m)
+	if err != nil {
+		return err
+	}
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, err := net.SplitHostPort(s.Addr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	if err = smtp.SendMail(s.Addr, auth, s.From, s.To, body); err != nil {
+		return fmt.Errorf("smtp: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// Split splits text to parts of at most limit bytes without breaking
+// UTF-8 characters, preferring to split on line breaks.
+func Split(s string, limit int) []string {
+	var parts []string
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		if i := strings.LastIndexByte(s[:cut], '\n'); i > 0 {
+			cut = i + 1
+		}
+		if cut == 0 {
+			// Limit is less than single character.
+			_, cut = utf8.DecodeRuneInString(s)
+		}
+		parts = append(parts, strings.TrimRight(s[:cut], "\n"))
+		s = s[cut:]
+	}
+	if s != "" || len(parts) == 0 {
+		parts = append(parts, s)
+	}
+	return parts
+}
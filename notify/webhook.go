@@ -0,0 +1,211 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Webhook posts messages as JSON to URL.
+//
+// Supported formats:
+//
+//	webhook: generic JSON object with all message fields
+//	slack:   Slack-compatible incoming webhook
+//	matrix:  Matrix-compatible (hookshot) webhook with text and html
+//
+// Files are not uploaded, only their names are listed.
+type Webhook struct {
+	URL    string
+	Format string
+	Client *http.Client
+	Retry  Retry
+}
+
+type webhookFile struct {
+	Name string `json:"name"`
+	Size int    `json:"size"`
+	URL  string `json:"url,omitempty"`
+}
+
+type webhookMessage struct {
+	Title       string        `json:"title"`
+	Description string        `json:"description"`
+	Color       int           `json:"color"`
+	Fields      []Field       `json:"fields,omitempty"`
+	Footer      string        `json:"footer,omitempty"`
+	Channel     string        `json:"channel,omitempty"`
+	Mention     string        `json:"mention,omitempty"`
+	Files       []webhookFile `json:"files,omitempty"`
+}
+
+type slackField struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+	Short bool   `json:"short"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Title  string       `json:"title,omitempty"`
+	Text   string       `json:"text,omitempty"`
+	Fields []slackField `json:"fields,omitempty"`
+	Footer string       `json:"footer,omitempty"`
+}
+
+type slackMessage struct {
+	Text        string            `json:"text"`
+	Channel     string            `json:"channel,omitempty"`
+	Attachments []slackAttachment `json:"attachments"`
+}
+
+type matrixMessage struct {
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
+
+// slackText converts markdown from Discord flavor to Slack one.
+func slackText(s string) string {
+	return strings.ReplaceAll(s, "**", "*")
+}
+
+// matrixHTML renders markdown-formatted text to html, supporting only
+// bold text and line breaks.
+func matrixHTML(s string) string {
+	parts := strings.Split(html.EscapeString(s), "**")
+	pairs := (len(parts) - 1) / 2 * 2
+	b := new(strings.Builder)
+	for i, p := range parts {
+		switch {
+		case i == 0:
+		case i > pairs:
+			// Unpaired delimiter.
+			b.WriteString("**")
+		case i%2 == 1:
+			b.WriteString("<b>")
+		default:
+			b.WriteString("</b>")
+		}
+		b.WriteString(p)
+	}
+	return strings.ReplaceAll(b.String(), "\n", "<br>\n")
+}
+
+func (w *Webhook) payload(m Message) interface{} {
+	switch w.Format {
+	case "slack":
+		a := slackAttachment{
+			Color:  fmt.Sprintf("#%06x", m.Color),
+			Text:   slackText(m.Description),
+			Footer: m.Footer,
+		}
+		for _, f := range m.Fields {
+			a.Fields = append(a.Fields, slackField{Title: f.Name, Value: slackText(f.Value)})
+		}
+		for _, f := range m.Files {
+			a.Fields = append(a.Fields, slackField{Title: "Attached", Value: fileName(f)})
+		}
+		text := "*" + m.Title + "*"
+		if m.Mention != "" {
+			text = m.Mention + " " + text
+		}
+		return slackMessage{
+			Text:        text,
+			Channel:     m.Channel,
+			Attachments: []slackAttachment{a},
+		}
+	case "matrix":
+		md := new(strings.Builder)
+		fmt.Fprintf(md, "**%s**\n\n%s\n", m.Title, strings.TrimSpace(m.Description))
+		for _, f := range m.Fields {
+			fmt.Fprintf(md, "\n**%s**: %s", f.Name, f.Value)
+		}
+		for _, f := range m.Files {
+			fmt.Fprintf(md, "\n**Attached**: %s", fileName(f))
+		}
+		if m.Footer != "" {
+			fmt.Fprintf(md, "\n\n%s", m.Footer)
+		}
+		msg := matrixMessage{
+			Text: m.Text(),
+			HTML: matrixHTML(md.String()),
+		}
+		if m.Mention != "" {
+			msg.Text = m.Mention + "\n" + msg.Text
+			msg.HTML = html.EscapeString(m.Mention) + "<br>\n" + msg.HTML
+		}
+		return msg
+	default:
+		msg := webhookMessage{
+			Title:       m.Title,
+			Description: m.Description,
+			Color:       m.Color,
+			Fields:      m.Fields,
+			Footer:      m.Footer,
+			Channel:     m.Channel,
+			Mention:     m.Mention,
+		}
+		for _, f := range m.Files {
+			msg.Files = append(msg.Files, webhookFile{Name: f.Name, Size: len(f.Data), URL: f.URL})
+		}
+		return msg
+	}
+}
+
+// retryAfter parses Retry-After header in seconds.
+func retryAfter(h http.Header) time.Duration {
+	seconds, err := strconv.Atoi(h.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+func (w *Webhook) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		return &StatusError{
+			Code:       res.StatusCode,
+			Status:     res.Status,
+			Body:       string(bytes.TrimSpace(msg)),
+			RetryAfter: retryAfter(res.Header),
+		}
+	}
+	return nil
+}
+
+// Notify posts message to webhook.
+func (w *Webhook) Notify(ctx context.Context, m Message) error {
+	body, err := json.Marshal(w.payload(m))
+	if err != nil {
+		return err
+	}
+	if err = w.Retry.Do(ctx, func() error {
+		return w.post(ctx, body)
+	}); err != nil {
+		return fmt.Errorf("%s: %v", w.Format, err)
+	}
+	return nil
+}
@@ -3,21 +3,86 @@ package resource
 import (
 	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/leonelquinteros/gotext"
 	"github.com/st-l10n/etree"
 )
 
 type Options struct {
 	Original    []byte   // "xml"
-	Translation [][]byte // ".po" files
+	Translation [][]byte // ".po" files, fuzzy entries are not baked
 	Code        string
 	Name        string
 	Font        string
 	Simplified  []string // see GenOptions.Simplified
+	Comment     string   // added before Language element if set, like provenance
+	// Fallback are parts, like "Reagents", where untranslated fields keep
+	// english text, or "*" for all parts. Tips can be set by tag "GameTip"
+	// or by their file "Tips". Records of other parts are removed if any
+	// field is untranslated, so the game falls back to english.
+	Fallback []string
 }
 
-const Blank = "{BLANK}"
+// fieldID returns msgid of field of record with key in part, see
+// GenOptions.Simplified.
+func (o Options) fieldID(part, key, field, engText string) string {
+	simplified := false
+	for _, s := range o.Simplified {
+		if s == part || s == part+"."+field {
+			simplified = true
+		}
+	}
+	if !simplified {
+		// Using original text as ID.
+		return engText
+	}
+	// Using simplified relative path as ID.
+	id := key
+	if field != "Value" {
+		id += "." + field
+	}
+	return id
+}
+
+// fallback reports whether untranslated fields of part keep english text.
+func (o Options) fallback(part string) bool {
+	for _, f := range o.Fallback {
+		if f == part || f == "*" || f == "Tips" && part == "GameTip" {
+			return true
+		}
+	}
+	return false
+}
+
+// translations are translated strings of catalogs by context and msgid.
+type translations map[[2]string]string
+
+// readTranslations reads po-formatted files, skipping untranslated and
+// fuzzy entries, so unreviewed suggestions are not baked.
+func readTranslations(files [][]byte) (translations, error) {
+	t := make(translations)
+	for _, data := range files {
+		entries, err := ReadCatalog("", data)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if e.Str == "" || e.Fuzzy {
+				continue
+			}
+			t[[2]string{e.Context, e.ID}] = e.Str
+		}
+	}
+	return t, nil
+}
+
+// get returns translation of id in context, or id if it is not translated.
+func (t translations) get(id, context string) string {
+	if s, ok := t[[2]string{context, id}]; ok {
+		return s
+	}
+	return id
+}
 
 // Bake generates new translation file.
 // Original is original english xml file, translation is po-formatted file.
@@ -27,9 +92,9 @@ func Bake(o Options) ([]byte, error) {
 		return nil, errors.New("no code provided")
 	}
 	translations, original := o.Translation, o.Original
-	t := &gotext.Po{}
-	for _, translation := range translations {
-		t.Parse(translation)
+	t, err := readTranslations(translations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse translation: %v", err)
 	}
 	eng := etree.NewDocument()
 	if err := eng.ReadFromBytes(original); err != nil {
@@ -48,34 +113,78 @@ func Bake(o Options) ([]byte, error) {
 	f := e.FindElement("Font")
 	if len(o.Font) > 0 {
 		if f == nil {
-			f = e.CreateElement("Font")
+			// Header elements must precede parts, see Validate.
+			f = etree.NewElement("Font")
+			var next etree.Token
+			children := e.ChildElements()
+			for i, child := range children {
+				if child.Tag == "Code" && i+1 < len(children) {
+					next = children[i+1]
+				}
+			}
+			e.InsertChild(next, f)
 		}
 		f.SetText(o.Font)
 	} else if f != nil {
 		e.RemoveChild(f)
 	}
 
+	// resolve returns translation of field of other record that is used by
+	// Use sentinel, or blank string if it is not translated.
+	resolve := func(use, field string) string {
+		for depth := 0; depth <= maxUseDepth; depth++ {
+			context, ok := ParseUse(use)
+			if !ok {
+				return use
+			}
+			parts := strings.SplitN(context, ".", 2)
+			if len(parts) != 2 {
+				return ""
+			}
+			engField := eng.FindElement("/Language/" + parts[0] + "/*[Key='" + parts[1] + "']/" + field)
+			if engField == nil {
+				return ""
+			}
+			id := o.fieldID(parts[0], parts[1], field, engField.Text())
+			switch use = t.get(id, context); use {
+			case "", id, Remove:
+				return ""
+			case Same:
+				return engField.Text()
+			}
+		}
+		// Cyclic.
+		return ""
+	}
 	for _, part := range e.ChildElements() {
 		switch part.Tag {
 		case "Name", "Code", "Font":
 			continue
 		}
-		simplified := false
-		for _, s := range o.Simplified {
-			if s == part.Tag {
-				simplified = true
-			}
-		}
+		fallback := o.fallback(part.Tag)
 	Loop:
 		for _, e := range part.ChildElements() {
 			k := e.SelectElement("Key")
 			if k == nil {
 				// Tips.
-				translated := t.Get(e.Text())
-				if translated == "" || translated == e.Text() {
+				translated := t.get(e.Text(), "")
+				switch translated {
+				case Same:
+					continue
+				case Remove:
 					part.RemoveChild(e)
 					continue
 				}
+				if _, isUse := ParseUse(translated); isUse {
+					// Tips have no context.
+					translated = ""
+				}
+				if translated == "" || translated == e.Text() {
+					if !fallback {
+						part.RemoveChild(e)
+					}
+					continue
+				}
 				e.SetText(translated)
 				continue
 			}
@@ -86,7 +195,6 @@ func Bake(o Options) ([]byte, error) {
 				continue
 			}
 			for _, elemPart := range e.ChildElements() {
-				elemSimplified := simplified
 				switch elemPart.Tag {
 				case "Key":
 					continue
@@ -97,33 +205,31 @@ func Bake(o Options) ([]byte, error) {
 					e.RemoveChild(elemPart)
 					continue
 				}
-				for _, s := range o.Simplified {
-					if s == part.Tag+"."+elemPart.Tag {
-						elemSimplified = true
-					}
-				}
 				p := elemPart.GetRelativePath(e)
 				engPart := engElem.FindElement(p)
 				if engPart == nil {
 					continue
 				}
-				engText := engPart.Text()
-				var id string
-				if elemPart.Tag != "Value" {
-					id += "." + elemPart.Tag
+				id := o.fieldID(part.Tag, elemKey, elemPart.Tag, engPart.Text())
+				translated := t.get(id, part.Tag+"."+elemKey)
+				used := false
+				if _, isUse := ParseUse(translated); isUse {
+					translated = resolve(translated, elemPart.Tag)
+					used = translated != ""
 				}
-				if elemSimplified {
-					// Using simplified relative path as ID.
-					id = elemKey
-					if elemPart.Tag != "Value" {
-						id += "." + elemPart.Tag
-					}
-				} else {
-					// Using original text as ID.
-					id = engText
+				switch translated {
+				case Same:
+					// Keeping english text.
+					continue
+				case Remove:
+					part.RemoveChild(e)
+					continue Loop
 				}
-				translated := t.GetC(id, part.Tag+"."+elemKey)
-				if translated == "" || translated == id {
+				if translated == "" || translated == id && !used {
+					if fallback {
+						// Keeping english text.
+						continue
+					}
 					part.RemoveChild(e)
 					continue Loop
 				}
@@ -136,6 +242,9 @@ func Bake(o Options) ([]byte, error) {
 			}
 		}
 	}
+	if o.Comment != "" {
+		d.InsertChild(e, etree.NewComment(" "+o.Comment+" "))
+	}
 	d.Indent(2)
 	return d.WriteToBytes()
 }
@@ -0,0 +1,263 @@
+package resource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/st-l10n/etree"
+)
+
+// ChangeType is type of record change between two versions of original file.
+type ChangeType string
+
+// Possible change types.
+const (
+	Added    ChangeType = "added"
+	Removed  ChangeType = "removed"
+	Modified ChangeType = "changed"
+)
+
+// Change of single record field between two versions of original xml.
+type Change struct {
+	Type  ChangeType `json:"type"`
+	File  string     `json:"file"` // like Entry.File
+	Part  string     `json:"part"`
+	Key   string     `json:"key,omitempty"` // blank for tips
+	Field string     `json:"field,omitempty"`
+	Old   string     `json:"old,omitempty"`
+	New   string     `json:"new,omitempty"`
+
+	// Languages is count of languages affected by change, see Affects.
+	Languages int `json:"languages"`
+}
+
+// Affects reports whether the translated entry is affected by change.
+//
+// For removed and changed records that means that translation is lost or
+// outdated, for added records that the entry translates new text.
+func (c Change) Affects(e Entry) bool {
+	if e.Str == "" || e.File != c.File {
+		return false
+	}
+	text := c.Old
+	if c.Type == Added {
+		text = c.New
+	}
+	if c.Key == "" {
+		// Tips.
+		return e.ID == text
+	}
+	return e.Context == c.Part+"."+c.Key && e.Original == text
+}
+
+type diffField struct {
+	File, Part, Key, Field string
+}
+
+type diffRecord struct {
+	field diffField
+	text  string
+}
+
+// readFields returns all record fields of original xml in document order.
+func readFields(original []byte, filePrefix string) ([]diffRecord, error) {
+	if len(original) == 0 {
+		return nil, nil
+	}
+	d := etree.NewDocument()
+	if err := d.ReadFromBytes(original); err != nil {
+		return nil, err
+	}
+	l := d.SelectElement("Language")
+	if l == nil {
+		return nil, fmt.Errorf("no language elem")
+	}
+	var records []diffRecord
+	for _, part := range l.ChildElements() {
+		switch part.Tag {
+		case "Name", "Code", "Font":
+			continue
+		}
+		for _, c := range part.ChildElements() {
+			k := c.SelectElement("Key")
+			if k == nil {
+				// Tips, using text as identifier.
+				records = append(records, diffRecord{
+					field: diffField{File: "Tips", Part: part.Tag, Field: c.Text()},
+					text:  c.Text(),
+				})
+				continue
+			}
+			for _, elemPart := range c.ChildElements() {
+				if elemPart.Tag == "Key" {
+					continue
+				}
+				text := elemPart.Text()
+				if text == "" {
+					text = Blank
+				}
+				records = append(records, diffRecord{
+					field: diffField{
+						File:  filePrefix + part.Tag,
+						Part:  part.Tag,
+						Key:   k.Text(),
+						Field: elemPart.Tag,
+					},
+					text: text,
+				})
+			}
+		}
+	}
+	return records, nil
+}
+
+// Diff returns list of changed record fields between old and new versions
+// of original xml. Any of versions can be blank, so all records are added
+// or removed.
+//
+// Tips have no keys, so they can only be added or removed.
+func Diff(old, new []byte, filePrefix string) ([]Change, error) {
+	oldRecords, err := readFields(old, filePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read old: %v", err)
+	}
+	newRecords, err := readFields(new, filePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read new: %v", err)
+	}
+	newText := make(map[diffField]string, len(newRecords))
+	for _, r := range newRecords {
+		newText[r.field] = r.text
+	}
+	oldText := make(map[diffField]string, len(oldRecords))
+	for _, r := range oldRecords {
+		oldText[r.field] = r.text
+	}
+	var changes []Change
+	for _, r := range oldRecords {
+		c := Change{
+			File: r.field.File,
+			Part: r.field.Part,
+			Key:  r.field.Key,
+			Old:  r.text,
+		}
+		if r.field.Key != "" {
+			c.Field = r.field.Field
+		}
+		text, ok := newText[r.field]
+		switch {
+		case !ok:
+			c.Type = Removed
+		case text != r.text:
+			c.Type = Modified
+			c.New = text
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	for _, r := range newRecords {
+		if _, ok := oldText[r.field]; ok {
+			continue
+		}
+		c := Change{
+			Type: Added,
+			File: r.field.File,
+			Part: r.field.Part,
+			Key:  r.field.Key,
+			New:  r.text,
+		}
+		if r.field.Key != "" {
+			c.Field = r.field.Field
+		}
+		changes = append(changes, c)
+	}
+	sortChanges(changes)
+	return changes, nil
+}
+
+func sortChanges(changes []Change) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Key != b.Key {
+			return a.Key < b.Key
+		}
+		return a.Field < b.Field
+	})
+}
+
+// isTranslation reports whether entry has translated text. Fuzzy,
+// identical to original and sentinel translations, like Same, are not
+// counted.
+func isTranslation(e Entry) bool {
+	return e.Str != "" && !e.Fuzzy && e.Str != e.Original && e.Str != e.ID && !IsSentinel(e.Str)
+}
+
+// DiffCatalogs returns changes of translations between old and new
+// catalogs of language: Added for newly translated entries, Modified for
+// revised translations and Removed for lost ones. Old and New of changes
+// are translations, and tips are matched by msgid.
+func DiffCatalogs(old, new Entries) []Change {
+	type catalogKey struct {
+		File, Context, ID string
+	}
+	key := func(e Entry) catalogKey {
+		return catalogKey{File: e.File, Context: e.Context, ID: e.ID}
+	}
+	change := func(e Entry) Change {
+		c := Change{File: e.File, Part: e.File}
+		if e.Context != "" {
+			parts := strings.SplitN(e.Context, ".", 2)
+			c.Part = parts[0]
+			if len(parts) == 2 {
+				c.Key = parts[1]
+			}
+			c.Field = entryField(e)
+		}
+		return c
+	}
+	newStr := make(map[catalogKey]string, len(new))
+	for _, e := range new {
+		if isTranslation(e) {
+			newStr[key(e)] = e.Str
+		}
+	}
+	oldStr := make(map[catalogKey]string, len(old))
+	var changes []Change
+	for _, e := range old {
+		if !isTranslation(e) {
+			continue
+		}
+		oldStr[key(e)] = e.Str
+		c := change(e)
+		c.Old = e.Str
+		str, ok := newStr[key(e)]
+		switch {
+		case !ok:
+			c.Type = Removed
+		case str != e.Str:
+			c.Type = Modified
+			c.New = str
+		default:
+			continue
+		}
+		changes = append(changes, c)
+	}
+	for _, e := range new {
+		if _, ok := oldStr[key(e)]; ok || !isTranslation(e) {
+			continue
+		}
+		// Duplicates are added once.
+		oldStr[key(e)] = ""
+		c := change(e)
+		c.Type = Added
+		c.New = e.Str
+		changes = append(changes, c)
+	}
+	sortChanges(changes)
+	return changes
+}
@@ -0,0 +1,96 @@
+package resource
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	old := read(t, "Language", "english.xml")
+	updated := bytes.Replace(old, []byte("<Value>Flour</Value>"), []byte("<Value>Wheat Flour</Value>"), 1)
+	updated = bytes.Replace(updated, []byte("<Key>Milk</Key>"), []byte("<Key>Soy</Key>"), 1)
+	changes, err := Diff(old, updated, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Type: Modified, File: "Reagents", Part: "Reagents", Key: "Flour", Field: "Value", Old: "Flour", New: "Wheat Flour"},
+		{Type: Removed, File: "Reagents", Part: "Reagents", Key: "Milk", Field: "Unit", Old: "ml"},
+		{Type: Removed, File: "Reagents", Part: "Reagents", Key: "Milk", Field: "Value", Old: "Milk"},
+		{Type: Added, File: "Reagents", Part: "Reagents", Key: "Soy", Field: "Unit", New: "ml"},
+		{Type: Added, File: "Reagents", Part: "Reagents", Key: "Soy", Field: "Value", New: "Milk"},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("unexpected changes: %+v", changes)
+	}
+	for i, c := range expected {
+		if changes[i] != c {
+			t.Errorf("%+v (got) != %+v (expected)", changes[i], c)
+		}
+	}
+	translated, err := Gen(GenOptions{
+		Original:   old,
+		Translated: read(t, "Language", "russian.xml"),
+		Simplified: testSimplifiedParts,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var affected int
+	for _, e := range translated {
+		if changes[0].Affects(e) {
+			affected++
+		}
+	}
+	if affected != 1 {
+		t.Errorf("unexpected affected count %d", affected)
+	}
+	t.Run("Blank", func(t *testing.T) {
+		changes, err := Diff(nil, old, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range changes {
+			if c.Type != Added {
+				t.Fatalf("unexpected change %+v", c)
+			}
+		}
+		if len(changes) != len(translated) {
+			t.Errorf("unexpected count %d != %d", len(changes), len(translated))
+		}
+	})
+}
+
+func TestDiffCatalogs(t *testing.T) {
+	old := Entries{
+		{File: "Keys", Context: "Keys.Jump", ID: "Jump", Original: "Jump", Str: "Прыжок"},
+		{File: "Keys", Context: "Keys.Crouch", ID: "Crouch", Original: "Crouch", Str: "Присесть"},
+		{File: "Keys", Context: "Keys.Sprint", ID: "Sprint", Original: "Sprint", Str: "Бег"},
+		{File: "Keys", Context: "Keys.Walk", ID: "Walk", Original: "Walk", Str: ""},
+		{File: "Keys", Context: "Keys.Ok", ID: "OK", Original: "OK", Str: "OK"},
+		{File: "Tips", ID: "Press {KEY:Jump} to jump", Original: "Press {KEY:Jump} to jump", Str: "Нажмите {KEY:Jump} для прыжка"},
+		{File: "Reagents", Context: "Reagents.Flour", ID: "Flour.Unit", Original: "g", Str: "г"},
+	}
+	new := Entries{
+		{File: "Keys", Context: "Keys.Jump", ID: "Jump", Original: "Jump", Str: "Прыжок"},
+		{File: "Keys", Context: "Keys.Crouch", ID: "Crouch", Original: "Crouch", Str: "Присесть", Fuzzy: true},
+		{File: "Keys", Context: "Keys.Sprint", ID: "Sprint", Original: "Sprint", Str: "Бежать"},
+		{File: "Keys", Context: "Keys.Walk", ID: "Walk", Original: "Walk", Str: "Шаг"},
+		{File: "Keys", Context: "Keys.Ok", ID: "OK", Original: "OK", Str: Same},
+		{File: "Keys", Context: "Keys.Fly", ID: "Fly", Original: "Fly", Str: "Fly"},
+		{File: "Tips", ID: "Press {KEY:Jump} to jump", Original: "Press {KEY:Jump} to jump", Str: "Нажмите {KEY:Jump}, чтобы прыгнуть"},
+		{File: "Reagents", Context: "Reagents.Flour", ID: "Flour.Unit", Original: "g", Str: "гр"},
+	}
+	got := DiffCatalogs(old, new)
+	expected := []Change{
+		{Type: Removed, File: "Keys", Part: "Keys", Key: "Crouch", Field: "Value", Old: "Присесть"},
+		{Type: Modified, File: "Keys", Part: "Keys", Key: "Sprint", Field: "Value", Old: "Бег", New: "Бежать"},
+		{Type: Added, File: "Keys", Part: "Keys", Key: "Walk", Field: "Value", New: "Шаг"},
+		{Type: Modified, File: "Reagents", Part: "Reagents", Key: "Flour", Field: "Unit", Old: "г", New: "гр"},
+		{Type: Modified, File: "Tips", Part: "Tips", Old: "Нажмите {KEY:Jump} для прыжка", New: "Нажмите {KEY:Jump}, чтобы прыгнуть"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected changes:\n%+v", got)
+	}
+}
@@ -17,6 +17,10 @@ type Entry struct {
 	Str               string `json:"str"`
 	Context           string `json:"context,omitempty"`
 	Original          string `json:"original"`
+	Fuzzy             bool   `json:"fuzzy,omitempty"`
+	// Field is tag of record field, like "Unit", of non-simplified entry of
+	// field other than "Value". Simplified entries have it in ID.
+	Field string `json:"field,omitempty"`
 }
 
 type Entries []Entry
@@ -167,9 +171,15 @@ func (e Entry) WriteTo(w io.Writer) error {
 	if len(e.TranslatorComment) > 0 {
 		fmt.Fprintf(w, "# %s\n", e.TranslatorComment)
 	}
+	if len(e.Field) > 0 {
+		fmt.Fprintf(w, "#. %s%s\n", fieldPrefix, e.Field)
+	}
 	if len(e.Reference) > 0 {
 		fmt.Fprintf(w, "#: %s\n", e.Reference)
 	}
+	if e.Fuzzy {
+		fmt.Fprint(w, "#, fuzzy\n")
+	}
 	if len(e.Context) > 0 {
 		fmt.Fprintf(w, "msgctxt %q\n", e.Context)
 	}
@@ -21,6 +21,12 @@ type GenOptions struct {
 	// The "Tips" part is always assumed as non-simplified.
 	Simplified []string
 	FilePrefix string
+
+	// If Same, translations that are identical to non-blank original are
+	// set to Same, as intentionally identical. Such translations are
+	// usually english leftovers of game files, so it is opt-in and should
+	// not be used for english.
+	Same bool
 }
 
 // Gen generates .po entry list from original xml, trying to apply translations
@@ -43,7 +49,7 @@ func Gen(o GenOptions) (Entries, error) {
 	}
 	g := l.SelectElement("GameTip")
 	if g != nil && len(g.Child) != 0 {
-		return genTips(eng, d)
+		return genTips(eng, d, o.Same)
 	}
 	for _, part := range l.ChildElements() {
 		switch part.Tag {
@@ -90,6 +96,9 @@ func Gen(o GenOptions) (Entries, error) {
 					if entry.Str == "" {
 						entry.Str = Blank
 					}
+					if o.Same && entry.Str == entry.Original && entry.Original != Blank {
+						entry.Str = Same
+					}
 				}
 				if elemSimplified {
 					// Using simplified relative path as ID.
@@ -100,6 +109,9 @@ func Gen(o GenOptions) (Entries, error) {
 				} else {
 					// Using original text as ID.
 					entry.ID = entry.Original
+					if elemPart.Tag != "Value" {
+						entry.Field = elemPart.Tag
+					}
 				}
 				if entry.ID != entry.Original {
 					entry.TranslatorComment = fmt.Sprintf("Original: %q", entry.Original)
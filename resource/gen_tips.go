@@ -71,7 +71,7 @@ func (t tip) equal(refs []reference) bool {
 	return true
 }
 
-func genTips(eng, d *etree.Document) (Entries, error) {
+func genTips(eng, d *etree.Document, same bool) (Entries, error) {
 	var tips []tip
 	for _, part := range eng.SelectElement("Language").ChildElements() {
 		switch part.Tag {
@@ -108,6 +108,9 @@ func genTips(eng, d *etree.Document) (Entries, error) {
 	}
 	var entries Entries
 	for _, tip := range tips {
+		if same && tip.translation == tip.raw {
+			tip.translation = Same
+		}
 		entries = append(entries, Entry{
 			File:      "Tips",
 			Reference: tip.codeReference,
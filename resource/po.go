@@ -0,0 +1,385 @@
+package resource
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const originalPrefix = "Original: "
+
+// fieldPrefix is prefix of extracted comment with Entry.Field.
+const fieldPrefix = "Field: "
+
+// ReadCatalog parses po-formatted file to entry list, setting Entry.File
+// to provided file name.
+//
+// The header entry (with blank msgid) and obsolete entries are skipped.
+// Entry.Original is restored from translator comment that is generated
+// for simplified entries, otherwise it is equal to Entry.ID.
+func ReadCatalog(file string, data []byte) (Entries, error) {
+	var (
+		entries Entries
+		entry   Entry
+		target  *string // current msgctxt, msgid or msgstr
+		hasID   bool
+		line    int
+	)
+	flush := func() {
+		if hasID && entry.ID != "" {
+			entry.File = file
+			if entry.Original == "" {
+				entry.Original = entry.ID
+			}
+			entries = append(entries, entry)
+		}
+		entry = Entry{}
+		target = nil
+		hasID = false
+	}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	s.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for s.Scan() {
+		line++
+		l := strings.TrimSpace(s.Text())
+		switch {
+		case l == "":
+			flush()
+		case strings.HasPrefix(l, "#~"):
+			// Obsolete entry.
+			continue
+		case strings.HasPrefix(l, "#,"):
+			for _, flag := range strings.Split(strings.TrimPrefix(l, "#,"), ",") {
+				if strings.TrimSpace(flag) == "fuzzy" {
+					entry.Fuzzy = true
+				}
+			}
+		case strings.HasPrefix(l, "#:"):
+			entry.Reference = strings.TrimSpace(strings.TrimPrefix(l, "#:"))
+		case strings.HasPrefix(l, "# "):
+			comment := strings.TrimSpace(strings.TrimPrefix(l, "# "))
+			entry.TranslatorComment = comment
+			if strings.HasPrefix(comment, originalPrefix) {
+				if original, err := strconv.Unquote(strings.TrimPrefix(comment, originalPrefix)); err == nil {
+					entry.Original = original
+				}
+			}
+		case strings.HasPrefix(l, "#. "+fieldPrefix):
+			entry.Field = strings.TrimSpace(strings.TrimPrefix(l, "#. "+fieldPrefix))
+		case strings.HasPrefix(l, "#"):
+			// Extracted comments, previous values and so on.
+			continue
+		case strings.HasPrefix(l, "msgctxt "):
+			// New entry can start without blank line.
+			if hasID {
+				flush()
+			}
+			target = &entry.Context
+			l = strings.TrimPrefix(l, "msgctxt ")
+		case strings.HasPrefix(l, "msgid "):
+			if hasID {
+				flush()
+			}
+			hasID = true
+			target = &entry.ID
+			l = strings.TrimPrefix(l, "msgid ")
+		case strings.HasPrefix(l, "msgstr "):
+			target = &entry.Str
+			l = strings.TrimPrefix(l, "msgstr ")
+		case strings.HasPrefix(l, "msgid_plural "), strings.HasPrefix(l, "msgstr["):
+			return nil, fmt.Errorf("line %d: plural forms are not supported", line)
+		}
+		if !strings.HasPrefix(l, `"`) {
+			continue
+		}
+		if target == nil {
+			return nil, fmt.Errorf("line %d: unexpected string", line)
+		}
+		v, err := unquote(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		*target += v
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+	return entries, nil
+}
+
+// unquote unquotes single po string line.
+func unquote(s string) (string, error) {
+	v, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("bad string %s: %v", s, err)
+	}
+	return v, nil
+}
+
+// HeaderField is field of po header entry, like "X-Generator: Martian".
+type HeaderField struct {
+	Name  string
+	Value string
+}
+
+func (f HeaderField) line() string {
+	return Escape(f.Name + ": " + f.Value + "\n")
+}
+
+// headerStart returns index of the first line of header entry value, or
+// -1 if there is no header entry.
+func headerStart(lines []string) int {
+	for i := 0; i+1 < len(lines); i++ {
+		l := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(l, "#") || l == "" {
+			continue
+		}
+		if l == `msgid ""` && strings.TrimSpace(lines[i+1]) == `msgstr ""` {
+			return i + 2
+		}
+		break
+	}
+	return -1
+}
+
+// HeaderValue returns value of header field in po-formatted data, or blank
+// string if there is no such field.
+func HeaderValue(data []byte, name string) string {
+	lines := strings.SplitAfter(string(data), "\n")
+	start := headerStart(lines)
+	if start < 0 {
+		return ""
+	}
+	for _, l := range lines[start:] {
+		l = strings.TrimSpace(l)
+		if !strings.HasPrefix(l, `"`) {
+			break
+		}
+		v, err := unquote(l)
+		if err != nil {
+			return ""
+		}
+		parts := strings.SplitN(v, ":", 2)
+		if len(parts) == 2 && strings.EqualFold(strings.TrimSpace(parts[0]), name) {
+			return strings.TrimSpace(parts[1])
+		}
+	}
+	return ""
+}
+
+// SetHeader sets fields of header entry in po-formatted data, replacing
+// values of existing fields and appending missing ones.
+//
+// Other lines are kept as is, so the result can be written over the file
+// produced by msgmerge.
+func SetHeader(data []byte, fields []HeaderField) ([]byte, error) {
+	lines := strings.SplitAfter(string(data), "\n")
+	start := headerStart(lines)
+	if start < 0 {
+		return nil, fmt.Errorf("no header entry")
+	}
+	end := start
+	set := make(map[string]bool, len(fields))
+	for ; end < len(lines); end++ {
+		l := strings.TrimSpace(lines[end])
+		if !strings.HasPrefix(l, `"`) {
+			break
+		}
+		v, err := unquote(l)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", end+1, err)
+		}
+		name := strings.TrimSpace(strings.SplitN(v, ":", 2)[0])
+		for _, f := range fields {
+			if strings.EqualFold(f.Name, name) {
+				lines[end] = f.line() + "\n"
+				set[f.Name] = true
+			}
+		}
+	}
+	var missing []string
+	for _, f := range fields {
+		if !set[f.Name] {
+			missing = append(missing, f.line()+"\n")
+		}
+	}
+	b := new(strings.Builder)
+	for _, l := range lines[:end] {
+		b.WriteString(l)
+	}
+	if end > 0 && !strings.HasSuffix(lines[end-1], "\n") {
+		b.WriteString("\n")
+	}
+	for _, l := range missing {
+		b.WriteString(l)
+	}
+	for _, l := range lines[end:] {
+		b.WriteString(l)
+	}
+	return []byte(b.String()), nil
+}
+
+// Translation is new translation of entry with context and msgid.
+type Translation struct {
+	Context string
+	ID      string
+	Str     string
+	Fuzzy   bool
+}
+
+// poEntry is position of entry lines in po file.
+type poEntry struct {
+	flags  int // "#," line or -1
+	insert int // line for missing flags, before "#|" or msgctxt
+	str    int // first msgstr line or -1
+	end    int // after last msgstr line
+	key    entryKey
+}
+
+// SetTranslations replaces msgstr of entries with matching context and
+// msgid in po-formatted data, adding fuzzy flag for fuzzy translations.
+// Flags of other translations are kept.
+//
+// Other lines are kept as is, like in SetHeader. Returns count of
+// changed entries.
+func SetTranslations(data []byte, translations []Translation) ([]byte, int, error) {
+	byKey := make(map[entryKey]Translation, len(translations))
+	for _, t := range translations {
+		byKey[entryKey{ID: t.ID, Context: t.Context}] = t
+	}
+	var (
+		lines   = strings.SplitAfter(string(data), "\n")
+		entries []poEntry
+		entry   = poEntry{flags: -1, insert: -1, str: -1}
+		target  *string
+		hasID   bool
+	)
+	flush := func() {
+		if hasID && entry.str >= 0 {
+			entries = append(entries, entry)
+		}
+		entry = poEntry{flags: -1, insert: -1, str: -1}
+		target = nil
+		hasID = false
+	}
+	for i, raw := range lines {
+		l := strings.TrimSpace(raw)
+		switch {
+		case l == "":
+			flush()
+			continue
+		case strings.HasPrefix(l, "#~"):
+			continue
+		case strings.HasPrefix(l, "#"):
+			if hasID {
+				flush()
+			}
+			if strings.HasPrefix(l, "#,") {
+				entry.flags = i
+			}
+			if strings.HasPrefix(l, "#|") && entry.insert < 0 {
+				entry.insert = i
+			}
+			continue
+		case strings.HasPrefix(l, "msgctxt "):
+			if hasID {
+				flush()
+			}
+			if entry.insert < 0 {
+				entry.insert = i
+			}
+			target = &entry.key.Context
+			l = strings.TrimPrefix(l, "msgctxt ")
+		case strings.HasPrefix(l, "msgid "):
+			if hasID {
+				flush()
+			}
+			if entry.insert < 0 {
+				entry.insert = i
+			}
+			hasID = true
+			target = &entry.key.ID
+			l = strings.TrimPrefix(l, "msgid ")
+		case strings.HasPrefix(l, "msgstr "):
+			entry.str = i
+			target = nil
+			l = ""
+		}
+		if entry.str >= 0 {
+			entry.end = i + 1
+		}
+		if target == nil || !strings.HasPrefix(l, `"`) {
+			continue
+		}
+		v, err := unquote(l)
+		if err != nil {
+			return nil, 0, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		*target += v
+	}
+	flush()
+	var changed int
+	// Replacing from the end keeps positions of previous entries.
+	for i := len(entries) - 1; i >= 0; i-- {
+		e := entries[i]
+		t, ok := byKey[e.key]
+		if !ok || e.key.ID == "" {
+			continue
+		}
+		changed++
+		str := []string{"msgstr " + Escape(t.Str) + "\n"}
+		lines = append(lines[:e.str], append(str, lines[e.end:]...)...)
+		if !t.Fuzzy {
+			continue
+		}
+		switch {
+		case e.flags < 0:
+			lines = append(lines[:e.insert], append([]string{"#, fuzzy\n"}, lines[e.insert:]...)...)
+		case !hasFlag(lines[e.flags], "fuzzy"):
+			lines[e.flags] = strings.TrimRight(lines[e.flags], "\r\n") + ", fuzzy\n"
+		}
+	}
+	return []byte(strings.Join(lines, "")), changed, nil
+}
+
+func hasFlag(line, flag string) bool {
+	for _, f := range strings.Split(strings.TrimPrefix(strings.TrimSpace(line), "#,"), ",") {
+		if strings.TrimSpace(f) == flag {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadCatalogs reads all po files of fsys, setting Entry.File to file
+// name without extension and prefix, see ReadCatalog.
+func ReadCatalogs(fsys fs.FS, prefix string) (Entries, error) {
+	var entries Entries
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(name, ".po") {
+			return nil
+		}
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		file := strings.TrimPrefix(strings.TrimSuffix(path.Base(name), ".po"), prefix)
+		got, err := ReadCatalog(file, data)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", name, err)
+		}
+		entries = append(entries, got...)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
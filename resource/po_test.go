@@ -0,0 +1,175 @@
+package resource
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestReadCatalog(t *testing.T) {
+	t.Run("Fuzzy", func(t *testing.T) {
+		entries, err := ReadCatalog("Colors", read(t, "merge_result.po"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 12 {
+			t.Fatalf("unexpected length %d", len(entries))
+		}
+		var fuzzy int
+		for _, e := range entries {
+			if e.File != "Colors" {
+				t.Errorf("unexpected file %q", e.File)
+			}
+			if e.Fuzzy {
+				fuzzy++
+			}
+		}
+		if fuzzy != 2 {
+			t.Errorf("unexpected fuzzy count %d", fuzzy)
+		}
+		pink := entries[9]
+		if pink.ID != "Pink (Color)" || pink.Context != "Colors.ColorPink" || pink.Str != "Розовый" {
+			t.Errorf("unexpected multiline entry %+v", pink)
+		}
+		s := entries.Stats()
+		if s.Total != 12 || s.Translated != 10 || s.Fuzzy != 2 {
+			t.Errorf("unexpected stats %+v", s)
+		}
+	})
+	t.Run("Original", func(t *testing.T) {
+		entries, err := ReadCatalog("Keys", read(t, "Keys-RU.po"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		none := entries[0]
+		if none.ID != "None" || none.Original != Blank {
+			t.Errorf("unexpected entry %+v", none)
+		}
+		s := entries.Stats()
+		if s.Translated+s.Fuzzy+s.Untranslated+s.Identical != s.Total {
+			t.Errorf("inconsistent stats %+v", s)
+		}
+	})
+	t.Run("RoundTrip", func(t *testing.T) {
+		var expected Entries
+		if err := json.Unmarshal(read(t, "tips.json"), &expected); err != nil {
+			t.Fatal(err)
+		}
+		expected[0].Fuzzy = true
+		buf := new(bytes.Buffer)
+		if err := expected.WriteFile("Tips", buf); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ReadCatalog("Tips", buf.Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != len(expected) {
+			t.Fatalf("unexpected length %d", len(got))
+		}
+		for i, e := range expected {
+			if e.Original == "" {
+				e.Original = e.ID
+			}
+			if got[i] != e {
+				t.Errorf("%+v (got) != %+v (expected)", got[i], e)
+			}
+		}
+	})
+}
+
+func TestSetHeader(t *testing.T) {
+	data := read(t, "merge_result.po")
+	fields := []HeaderField{
+		{Name: "X-Generator", Value: "Martian"},
+		{Name: "X-Game-Version", Value: "0.2.1234"},
+		{Name: "X-Source-Commit", Value: "e3b0c44"},
+	}
+	got, err := SetHeader(data, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`"X-Generator: Martian\n"`,
+		`"X-Game-Version: 0.2.1234\n"`,
+		`"X-Source-Commit: e3b0c44\n"`,
+	} {
+		if bytes.Count(got, []byte(s)) != 1 {
+			t.Errorf("%s not found once", s)
+		}
+	}
+	entries, err := ReadCatalog("Colors", got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 12 {
+		t.Errorf("unexpected length %d", len(entries))
+	}
+	again, err := SetHeader(got, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, got) {
+		t.Error("should be idempotent")
+	}
+	if _, err = SetHeader([]byte("msgid \"a\"\nmsgstr \"b\"\n"), fields); err == nil {
+		t.Error("should fail without header")
+	}
+	if v := HeaderValue(got, "x-game-version"); v != "0.2.1234" {
+		t.Errorf("unexpected value %q", v)
+	}
+	if v := HeaderValue(got, "X-Unknown"); v != "" {
+		t.Errorf("unexpected value %q", v)
+	}
+}
+
+func TestSetTranslations(t *testing.T) {
+	data := read(t, "merge_result.po")
+	data = append(data, `
+#: /Language/Colors/Record[Key='ColorTeal']
+#, c-format
+#| msgid "Teal"
+msgctxt "Colors.ColorTeal"
+msgid "Teal"
+msgstr ""
+"Бирю"
+"зовый"
+`...)
+	got, changed, err := SetTranslations(data, []Translation{
+		{Context: "Colors.ColorKhaki", ID: "Khaki", Str: "Хаки!", Fuzzy: true},
+		{Context: "Colors.ColorPink", ID: "Pink (Color)", Str: "Розовый\n", Fuzzy: true},
+		{Context: "Colors.ColorTeal", ID: "Teal", Str: "Бирюзовый", Fuzzy: true},
+		{Context: "Colors.ColorGray", ID: "Gray", Str: "Серенький"},
+		{Context: "Colors.ColorMissing", ID: "Missing", Str: "Нет"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if changed != 4 {
+		t.Errorf("unexpected changed count %d", changed)
+	}
+	for _, s := range []string{
+		"#, fuzzy\nmsgctxt \"Colors.ColorKhaki\"\nmsgid \"Khaki\"\nmsgstr \"Хаки!\"\n\n",
+		"#, fuzzy\nmsgctxt \"Colors.ColorPink\"\nmsgid \"\"\n\"Pink (Color)\"\nmsgstr \"Розовый\\n\"\n\n",
+		"#, c-format, fuzzy\n#| msgid \"Teal\"\nmsgctxt \"Colors.ColorTeal\"\nmsgid \"Teal\"\nmsgstr \"Бирюзовый\"\n",
+		"msgctxt \"Colors.ColorGray\"\nmsgid \"Gray\"\nmsgstr \"Серенький\"\n\n",
+		"#: /Language/Colors/Record[Key='ColorPurple']\nmsgctxt \"Colors.ColorPurple\"\nmsgid \"Purple\"\nmsgstr \"Фиолетовый\"\n",
+	} {
+		if !bytes.Contains(got, []byte(s)) {
+			t.Errorf("%q not found", s)
+		}
+	}
+	entries, err := ReadCatalog("Colors", got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 13 {
+		t.Fatalf("unexpected length %d", len(entries))
+	}
+	if s := entries.Stats(); s.Fuzzy != 4 {
+		t.Errorf("unexpected stats %+v", s)
+	}
+	if _, changed, _ = SetTranslations(data, nil); changed != 0 {
+		t.Errorf("unexpected changed count %d", changed)
+	}
+}
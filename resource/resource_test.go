@@ -189,6 +189,192 @@ func TestBake(t *testing.T) {
 			t.Error("failed")
 		}
 	})
+	t.Run("Fallback", func(t *testing.T) {
+		original := []byte(`<Language>
+  <Code>EN</Code>
+  <Reagents>
+    <RecordReagent>
+      <Key>Flour</Key>
+      <Value>Flour</Value>
+      <Unit>g</Unit>
+    </RecordReagent>
+  </Reagents>
+  <GameTip>
+    <String>Press {KEY:Jetpack} to fly.</String>
+  </GameTip>
+</Language>`)
+		translation := []byte(`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgctxt "Reagents.Flour"
+msgid "Flour"
+msgstr "Мука"
+`)
+		for _, tt := range []struct {
+			Fallback []string
+			Contains []string
+			Missing  []string
+		}{
+			{
+				Missing: []string{"Мука", "<Unit>", "<String>"},
+			},
+			{
+				Fallback: []string{"Reagents"},
+				Contains: []string{"<Value>Мука</Value>", "<Unit>g</Unit>"},
+				Missing:  []string{"<String>"},
+			},
+			{
+				Fallback: []string{"*"},
+				Contains: []string{"<Value>Мука</Value>", "<Unit>g</Unit>", "<String>Press {KEY:Jetpack} to fly.</String>"},
+			},
+		} {
+			result, err := Bake(Options{
+				Original:    original,
+				Translation: [][]byte{translation},
+				Code:        "RU",
+				Fallback:    tt.Fallback,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.Contains {
+				if !bytes.Contains(result, []byte(s)) {
+					t.Errorf("%v: %s not found in:\n%s", tt.Fallback, s, result)
+				}
+			}
+			for _, s := range tt.Missing {
+				if bytes.Contains(result, []byte(s)) {
+					t.Errorf("%v: unexpected %s in:\n%s", tt.Fallback, s, result)
+				}
+			}
+		}
+	})
+	t.Run("Same", func(t *testing.T) {
+		original := []byte(`<Language>
+  <Code>EN</Code>
+  <Interface>
+    <Record>
+      <Key>ButtonOk</Key>
+      <Value>OK</Value>
+    </Record>
+    <Record>
+      <Key>ButtonCancel</Key>
+      <Value>Cancel</Value>
+    </Record>
+  </Interface>
+</Language>`)
+		translated := bytes.Replace(original, []byte("Cancel</Value>"), []byte("Отмена</Value>"), 1)
+		entries, err := Gen(GenOptions{
+			Original:   original,
+			Translated: translated,
+			Same:       true,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entries[0].ID != "OK" || entries[0].Str != Same {
+			t.Fatalf("unexpected entries %+v", entries)
+		}
+		if s := entries.Stats(); s.Translated != 2 || s.Identical != 0 {
+			t.Errorf("unexpected stats %+v", s)
+		}
+		if len(entries.DifferentFromOriginal()) != 2 {
+			t.Error("same entry should be translated")
+		}
+		po := new(bytes.Buffer)
+		if err = entries.WriteFile("Interface", po); err != nil {
+			t.Fatal(err)
+		}
+		result, err := Bake(Options{
+			Original:    original,
+			Translation: [][]byte{po.Bytes()},
+			Code:        "RU",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, s := range []string{"<Value>OK</Value>", "<Value>Отмена</Value>"} {
+			if !bytes.Contains(result, []byte(s)) {
+				t.Errorf("%s not found in:\n%s", s, result)
+			}
+		}
+		if bytes.Contains(result, []byte(Same)) {
+			t.Errorf("unexpected %s in:\n%s", Same, result)
+		}
+	})
+	t.Run("Fuzzy", func(t *testing.T) {
+		original := []byte(`<Language>
+  <Code>EN</Code>
+  <Interface>
+    <Record>
+      <Key>Output</Key>
+      <Value>Output</Value>
+    </Record>
+    <Record>
+      <Key>Input</Key>
+      <Value>Input</Value>
+    </Record>
+  </Interface>
+</Language>`)
+		translation := []byte(`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgctxt "Interface.Output"
+msgid "Output"
+msgstr "Вывод"
+
+msgctxt "Interface.Input"
+msgid "Input"
+msgstr "Ввод"
+`)
+		// Propagated suggestion, like by "check consistency --propagate".
+		translation, changed, err := SetTranslations(translation, []Translation{
+			{Context: "Interface.Output", ID: "Output", Str: "Выход", Fuzzy: true},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if changed != 1 {
+			t.Fatalf("unexpected changed count %d", changed)
+		}
+		for _, tt := range []struct {
+			Fallback []string
+			Contains []string
+			Missing  []string
+		}{
+			{
+				Contains: []string{"<Value>Ввод</Value>"},
+				Missing:  []string{"Выход", "Вывод", "<Key>Output</Key>"},
+			},
+			{
+				Fallback: []string{"Interface"},
+				Contains: []string{"<Value>Ввод</Value>", "<Value>Output</Value>"},
+				Missing:  []string{"Выход", "Вывод"},
+			},
+		} {
+			result, err := Bake(Options{
+				Original:    original,
+				Translation: [][]byte{translation},
+				Code:        "RU",
+				Fallback:    tt.Fallback,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, s := range tt.Contains {
+				if !bytes.Contains(result, []byte(s)) {
+					t.Errorf("%v: %s not found in:\n%s", tt.Fallback, s, result)
+				}
+			}
+			for _, s := range tt.Missing {
+				if bytes.Contains(result, []byte(s)) {
+					t.Errorf("%v: unexpected %s in:\n%s", tt.Fallback, s, result)
+				}
+			}
+		}
+	})
 }
 
 var testSimplifiedParts = []string{
@@ -530,3 +716,22 @@ func TestLoad(t *testing.T) {
 		}
 	})
 }
+
+func TestOptionsFallback(t *testing.T) {
+	for _, tt := range []struct {
+		Fallback []string
+		Part     string
+		Expected bool
+	}{
+		{Fallback: []string{"Reagents"}, Part: "Reagents", Expected: true},
+		{Fallback: []string{"Reagents"}, Part: "Things"},
+		{Fallback: []string{"*"}, Part: "Things", Expected: true},
+		{Fallback: []string{"GameTip"}, Part: "GameTip", Expected: true},
+		{Fallback: []string{"Tips"}, Part: "GameTip", Expected: true},
+		{Fallback: []string{"Tips"}, Part: "Things"},
+	} {
+		if got := (Options{Fallback: tt.Fallback}).fallback(tt.Part); got != tt.Expected {
+			t.Errorf("fallback %v of %s: %v", tt.Fallback, tt.Part, got)
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package resource
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Sentinels are special translations that express intent of translator
+// instead of text.
+const (
+	// Blank is intentionally empty translation.
+	Blank = "{BLANK}"
+	// Same is translation that is intentionally identical to original, like
+	// "OK" or product name, so it is not treated as untranslated.
+	Same = "{SAME}"
+	// Remove removes record from baked file, so the game falls back to
+	// english for the whole record.
+	Remove = "{REMOVE}"
+)
+
+const usePrefix = "{USE:"
+
+// Use returns sentinel that uses translation of the same field of other
+// record with context, like "Things.ItemFlour".
+func Use(context string) string {
+	return usePrefix + context + "}"
+}
+
+// ParseUse returns context of sentinel returned by Use.
+func ParseUse(s string) (string, bool) {
+	if !strings.HasPrefix(s, usePrefix) || !strings.HasSuffix(s, "}") {
+		return "", false
+	}
+	context := strings.TrimSuffix(strings.TrimPrefix(s, usePrefix), "}")
+	if context == "" || strings.ContainsAny(context, "{} \n") {
+		return "", false
+	}
+	return context, true
+}
+
+// IsSentinel reports whether translation is sentinel.
+func IsSentinel(s string) bool {
+	switch s {
+	case Blank, Same, Remove:
+		return true
+	}
+	_, ok := ParseUse(s)
+	return ok
+}
+
+// sentinelLike matches values that look like sentinels, like "{BLANC}".
+var sentinelLike = regexp.MustCompile(`{(BLANK|SAME|REMOVE|USE:[^{}]*|[A-Z]+)}`)
+
+// maxUseDepth limits chains of Use sentinels.
+const maxUseDepth = 8
+
+// SentinelError is invalid sentinel in translation of entry.
+type SentinelError struct {
+	Entry   Entry
+	Message string
+}
+
+func (e SentinelError) Error() string {
+	return e.Entry.Context + ": " + e.Message
+}
+
+// ValidateSentinels returns errors of sentinels in translations of
+// entries: unknown sentinels, sentinels mixed with text, and Use of
+// missing, untranslated or cyclic contexts, or in entries without context.
+//
+// Placeholders of original, like {BLANK} or {0}, are not sentinels.
+func ValidateSentinels(entries Entries) []SentinelError {
+	type field struct {
+		Context string
+		Field   string
+	}
+	var (
+		errs   []SentinelError
+		fields = make(map[field]Entry)
+	)
+	for _, e := range entries {
+		if e.Context != "" {
+			fields[field{Context: e.Context, Field: entryField(e)}] = e
+		}
+	}
+	for _, e := range entries {
+		if e.Str == "" || e.Fuzzy {
+			continue
+		}
+		add := func(format string, args ...interface{}) {
+			errs = append(errs, SentinelError{Entry: e, Message: fmt.Sprintf(format, args...)})
+		}
+		context, isUse := ParseUse(e.Str)
+		switch {
+		case isUse && e.Context == "":
+			add("%s requires context", e.Str)
+		case isUse:
+			visited := map[string]bool{e.Context: true}
+			target, ok := fields[field{Context: context, Field: entryField(e)}]
+			for depth := 0; ok; depth++ {
+				if visited[target.Context] || depth > maxUseDepth {
+					add("cyclic %s", e.Str)
+					break
+				}
+				visited[target.Context] = true
+				next, isNext := ParseUse(target.Str)
+				if !isNext {
+					if target.Str == "" || target.Fuzzy || target.Str == Remove {
+						add("%s is not translated", target.Context)
+					}
+					break
+				}
+				target, ok = fields[field{Context: next, Field: entryField(e)}]
+				context = next
+			}
+			if !ok {
+				add("no %s with %s field", context, entryField(e))
+			}
+		case IsSentinel(e.Str):
+		default:
+			for _, s := range sentinelLike.FindAllString(e.Str, -1) {
+				if strings.Contains(e.Original, s) {
+					continue
+				}
+				if IsSentinel(s) || strings.HasPrefix(s, usePrefix) {
+					add("%s must be the whole translation", s)
+				} else {
+					add("unknown sentinel %s", s)
+				}
+			}
+		}
+	}
+	return errs
+}
+
+// entryField returns field of entry, like "Unit" for simplified "Flour.Unit"
+// msgid, or "Value".
+func entryField(e Entry) string {
+	if e.Field != "" {
+		return e.Field
+	}
+	key := strings.TrimPrefix(e.Context, strings.SplitN(e.Context, ".", 2)[0]+".")
+	if strings.HasPrefix(e.ID, key+".") {
+		return strings.TrimPrefix(e.ID, key+".")
+	}
+	return "Value"
+}
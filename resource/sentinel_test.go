@@ -0,0 +1,169 @@
+package resource
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestValidateSentinels(t *testing.T) {
+	entries := Entries{
+		{Context: "Things.ItemFlour", ID: "Flour", Original: "Flour", Str: "Мука"},
+		{Context: "Reagents.Flour", ID: "Flour", Original: "Flour", Str: Use("Things.ItemFlour")},
+		{Context: "Reagents.Flour", ID: "Flour.Unit", Original: "g", Str: Use("Reagents.Milk")},
+		{Context: "Reagents.Milk", ID: "Milk", Original: "Milk", Str: Use("Reagents.Flour")},
+		{Context: "Reagents.Milk", ID: "Milk.Unit", Original: "ml", Str: "мл"},
+		{Context: "Reagents.Egg", ID: "Egg", Original: "Egg", Str: Use("Reagents.Egg")},
+		{Context: "Reagents.Iron", ID: "Iron", Original: "Iron", Str: Use("Things.ItemIron")},
+		{Context: "Things.ItemIron", ID: "Iron", Original: "Iron", Str: ""},
+		{Context: "Reagents.Gold", ID: "Gold", Original: "Gold", Str: Use("Reagents.Missing")},
+		{Context: "Keys.A", ID: "A", Original: "A", Str: Same},
+		{Context: "Keys.B", ID: "B", Original: "B", Str: "Бэ {SAME}"},
+		{Context: "Keys.C", ID: "C", Original: "C {BLANK}", Str: "Цэ {BLANK}"},
+		{Context: "Keys.D", ID: "D", Original: "D", Str: "{BLANC}"},
+		{Context: "Keys.E", ID: "E", Original: "E", Str: Remove},
+		{File: "Tips", ID: "Tip", Original: "Tip", Str: Use("Keys.A")},
+	}
+	var got []string
+	for _, err := range ValidateSentinels(entries) {
+		got = append(got, err.Error())
+	}
+	expected := []string{
+		"Reagents.Egg: cyclic {USE:Reagents.Egg}",
+		"Reagents.Iron: Things.ItemIron is not translated",
+		"Reagents.Gold: no Reagents.Missing with Value field",
+		"Keys.B: {SAME} must be the whole translation",
+		"Keys.D: unknown sentinel {BLANC}",
+		": {USE:Keys.A} requires context",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected errors:\n%s", strings.Join(got, "\n"))
+	}
+	for s, expected := range map[string]bool{
+		Blank: true, Same: true, Remove: true, "{USE:Keys.A}": true,
+		"{USE:}": false, "{USE:Keys A}": false, "{KEY:Jetpack}": false, "": false,
+	} {
+		if IsSentinel(s) != expected {
+			t.Errorf("IsSentinel(%q) != %v", s, expected)
+		}
+	}
+}
+
+func TestBakeSentinels(t *testing.T) {
+	original := []byte(`<Language>
+  <Code>EN</Code>
+  <Things>
+    <Record>
+      <Key>ItemFlour</Key>
+      <Value>Flour</Value>
+    </Record>
+  </Things>
+  <Reagents>
+    <RecordReagent>
+      <Key>Flour</Key>
+      <Value>Flour Reagent</Value>
+      <Unit>g</Unit>
+    </RecordReagent>
+    <RecordReagent>
+      <Key>Milk</Key>
+      <Value>Milk</Value>
+      <Unit>ml</Unit>
+    </RecordReagent>
+    <RecordReagent>
+      <Key>Egg</Key>
+      <Value>Egg</Value>
+      <Unit>g</Unit>
+    </RecordReagent>
+  </Reagents>
+</Language>`)
+	entries := Entries{
+		{File: "Things", Context: "Things.ItemFlour", ID: "Flour", Str: "Мука"},
+		{File: "Reagents", Context: "Reagents.Flour", ID: "Flour Reagent", Str: Use("Things.ItemFlour")},
+		{File: "Reagents", Context: "Reagents.Flour", ID: "Flour.Unit", Str: "г"},
+		{File: "Reagents", Context: "Reagents.Milk", ID: "Milk", Str: "Молоко"},
+		{File: "Reagents", Context: "Reagents.Milk", ID: "Milk.Unit", Str: Use("Reagents.Egg")},
+		{File: "Reagents", Context: "Reagents.Egg", ID: "Egg", Str: Remove},
+		{File: "Reagents", Context: "Reagents.Egg", ID: "Egg.Unit", Str: Same},
+	}
+	var translations [][]byte
+	for _, file := range []string{"Things", "Reagents"} {
+		buf := new(bytes.Buffer)
+		if err := entries.WriteFile(file, buf); err != nil {
+			t.Fatal(err)
+		}
+		translations = append(translations, buf.Bytes())
+	}
+	result, err := Bake(Options{
+		Original:    original,
+		Translation: translations,
+		Code:        "RU",
+		Simplified:  []string{"Reagents.Unit"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		"<Key>Flour</Key>\n      <Value>Мука</Value>\n      <Unit>г</Unit>",
+		"<Key>Milk</Key>\n      <Value>Молоко</Value>\n      <Unit>g</Unit>",
+	} {
+		if !bytes.Contains(result, []byte(s)) {
+			t.Errorf("%s not found in:\n%s", s, result)
+		}
+	}
+	if bytes.Contains(result, []byte("<Key>Egg</Key>")) || bytes.Contains(result, []byte("{")) {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+}
+
+func TestValidateSentinelsFields(t *testing.T) {
+	original := []byte(`<Language>
+  <Code>EN</Code>
+  <Reagents>
+    <RecordReagent>
+      <Key>Flour</Key>
+      <Value>Flour</Value>
+      <Description>Ground grain</Description>
+    </RecordReagent>
+    <RecordReagent>
+      <Key>Bread</Key>
+      <Value>Bread</Value>
+      <Description>Baked flour</Description>
+    </RecordReagent>
+  </Reagents>
+</Language>`)
+	generated, err := Gen(GenOptions{Original: original})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err = generated.WriteFile("Reagents", buf); err != nil {
+		t.Fatal(err)
+	}
+	entries, err := ReadCatalog("Reagents", buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 4 {
+		t.Fatalf("unexpected entries %+v", entries)
+	}
+	translations := map[string]string{
+		"Flour":        "Мука",
+		"Bread":        Use("Reagents.Flour"),
+		"Baked flour":  Use("Reagents.Flour"),
+		"Ground grain": "",
+	}
+	fields := map[string]string{
+		"Ground grain": "Description",
+		"Baked flour":  "Description",
+	}
+	for i, e := range entries {
+		entries[i].Str = translations[e.ID]
+		if e.Field != fields[e.ID] {
+			t.Errorf("unexpected field %q of %q", e.Field, e.ID)
+		}
+	}
+	errs := ValidateSentinels(entries)
+	if len(errs) != 1 || errs[0].Entry.ID != "Baked flour" || errs[0].Message != "Reagents.Flour is not translated" {
+		t.Errorf("unexpected errors %+v", errs)
+	}
+}
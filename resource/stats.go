@@ -0,0 +1,82 @@
+package resource
+
+import (
+	"strings"
+)
+
+// Stats is translation progress summary.
+//
+// Every entry is counted exactly once in Translated, Fuzzy, Untranslated
+// or Identical, so they sum up to Total. Entries translated with sentinels,
+// like Same or Remove, are counted as Translated.
+type Stats struct {
+	Total        int `json:"total"`
+	Translated   int `json:"translated"`
+	Fuzzy        int `json:"fuzzy"`
+	Untranslated int `json:"untranslated"`
+	// Identical entries are translated with the same text as original,
+	// and are treated by Bake as untranslated.
+	Identical int `json:"identical"`
+
+	Words           int `json:"words"`            // words in original text
+	TranslatedWords int `json:"translated_words"` // words in original text of translated entries
+}
+
+// Add returns sum of s and b.
+func (s Stats) Add(b Stats) Stats {
+	return Stats{
+		Total:           s.Total + b.Total,
+		Translated:      s.Translated + b.Translated,
+		Fuzzy:           s.Fuzzy + b.Fuzzy,
+		Untranslated:    s.Untranslated + b.Untranslated,
+		Identical:       s.Identical + b.Identical,
+		Words:           s.Words + b.Words,
+		TranslatedWords: s.TranslatedWords + b.TranslatedWords,
+	}
+}
+
+// Progress returns percentage of translated entries.
+func (s Stats) Progress() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return float64(s.Translated) * 100 / float64(s.Total)
+}
+
+func countWords(s string) int {
+	if s == Blank {
+		return 0
+	}
+	return len(strings.Fields(s))
+}
+
+// Stats returns translation progress summary of entries.
+func (e Entries) Stats() Stats {
+	var s Stats
+	for _, entry := range e {
+		words := countWords(entry.Original)
+		s.Total++
+		s.Words += words
+		switch {
+		case entry.Str == "":
+			s.Untranslated++
+		case entry.Fuzzy:
+			s.Fuzzy++
+		case entry.Str == entry.Original || entry.Str == entry.ID:
+			s.Identical++
+		default:
+			s.Translated++
+			s.TranslatedWords += words
+		}
+	}
+	return s
+}
+
+// StatsByFile returns translation progress summary for each file.
+func (e Entries) StatsByFile() map[string]Stats {
+	files := make(map[string]Stats)
+	for _, entry := range e {
+		files[entry.File] = files[entry.File].Add(Entries{entry}.Stats())
+	}
+	return files
+}
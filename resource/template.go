@@ -0,0 +1,78 @@
+package resource
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// Template is original (english) language file.
+type Template struct {
+	Postfix string // like "_keys.xml"
+	Path    string // slash-separated directory, like "Language"
+}
+
+// Name returns file name for language prefix, like "russian_keys.xml".
+func (t Template) Name(prefix string) string {
+	return prefix + t.Postfix
+}
+
+func (t Template) String() string {
+	return path.Join(t.Path, t.Name("english"))
+}
+
+// FilePrefix returns prefix of Entry.File for template.
+//
+// Scenario/EscapeFromMars/Language/english_mars_mission.xml -> EscapeFromMars
+// Language -> ""
+func (t Template) FilePrefix() string {
+	var prefix string
+	for _, s := range strings.Split(t.Path, "/") {
+		if s != "Language" && s != "." {
+			prefix = s
+		}
+	}
+	return prefix
+}
+
+// Gen reads original file and translated file with provided prefix
+// from fsys and generates entries, see Gen.
+//
+// Missing translated file is treated as blank one. The Original,
+// Translated and FilePrefix options are set by Gen.
+func (t Template) Gen(fsys fs.FS, prefix string, o GenOptions) (Entries, error) {
+	var err error
+	if o.Original, err = fs.ReadFile(fsys, path.Join(t.Path, t.Name("english"))); err != nil {
+		return nil, fmt.Errorf("failed to read english translation file: %v", err)
+	}
+	o.Translated, err = fs.ReadFile(fsys, path.Join(t.Path, t.Name(prefix)))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read translated file: %v", err)
+	}
+	o.FilePrefix = t.FilePrefix()
+	return Gen(o)
+}
+
+// FindTemplates returns all original files in fsys.
+func FindTemplates(fsys fs.FS) ([]Template, error) {
+	var templates []Template
+	if err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		base := path.Base(name)
+		if d.IsDir() || !strings.HasPrefix(base, "english") || !strings.HasSuffix(base, ".xml") {
+			return nil
+		}
+		templates = append(templates, Template{
+			Postfix: strings.TrimPrefix(base, "english"),
+			Path:    path.Dir(name),
+		})
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
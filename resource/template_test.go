@@ -0,0 +1,47 @@
+package resource
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestFindTemplates(t *testing.T) {
+	fsys := fstest.MapFS{
+		"Language/english.xml":                              {Data: read(t, "Language", "english.xml")},
+		"Language/russian.xml":                              {Data: read(t, "Language", "russian.xml")},
+		"Scenario/EscapeFromMars/Language/english_tips.xml": {Data: read(t, "Language", "english_tips.xml")},
+	}
+	templates, err := FindTemplates(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Template{
+		{Path: "Language", Postfix: ".xml"},
+		{Path: "Scenario/EscapeFromMars/Language", Postfix: "_tips.xml"},
+	}
+	if len(templates) != len(expected) {
+		t.Fatalf("unexpected templates %v", templates)
+	}
+	for i, tpl := range expected {
+		if templates[i] != tpl {
+			t.Errorf("%v (got) != %v (expected)", templates[i], tpl)
+		}
+	}
+	if p := templates[1].FilePrefix(); p != "EscapeFromMars" {
+		t.Errorf("unexpected prefix %q", p)
+	}
+	entries, err := templates[0].Gen(fsys, "russian", GenOptions{Simplified: testSimplifiedParts})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries.TranslatedCount() == 0 {
+		t.Error("no translations")
+	}
+	entries, err = templates[1].Gen(fsys, "russian", GenOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 || entries.TranslatedCount() != 0 {
+		t.Error("unexpected translations")
+	}
+}
@@ -0,0 +1,213 @@
+package resource
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/st-l10n/etree"
+)
+
+// Header are elements of Language that precede parts, in order.
+var Header = []string{"Name", "Code", "Font"}
+
+// Part describes part of language file, like "Keys".
+type Part struct {
+	Tag     string   `mapstructure:"tag"`
+	Records []string `mapstructure:"records"` // tags of records, like "Record"
+	Fields  []string `mapstructure:"fields"`  // tags of record fields, like "Value"
+	Keyed   bool     `mapstructure:"keyed"`   // records have unique non-empty Key
+}
+
+// Schema describes structure of language file that is expected by game.
+type Schema struct {
+	Parts []Part `mapstructure:"parts"`
+}
+
+// Part returns part by tag.
+func (s *Schema) Part(tag string) (Part, bool) {
+	for _, p := range s.Parts {
+		if p.Tag == tag {
+			return p, true
+		}
+	}
+	return Part{}, false
+}
+
+func appendNew(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// InferSchema returns schema of english language file, allowing parts,
+// records and fields that are present in it.
+func InferSchema(original []byte) (*Schema, error) {
+	d := etree.NewDocument()
+	if err := d.ReadFromBytes(original); err != nil {
+		return nil, fmt.Errorf("failed to parse original: %v", err)
+	}
+	root := d.SelectElement("Language")
+	if root == nil {
+		return nil, fmt.Errorf("no Language element")
+	}
+	s := &Schema{}
+	for _, part := range root.ChildElements() {
+		if StringIn(part.Tag, Header) {
+			continue
+		}
+		p := Part{
+			Tag:   part.Tag,
+			Keyed: true,
+		}
+		for _, r := range part.ChildElements() {
+			p.Records = appendNew(p.Records, r.Tag)
+			if r.SelectElement("Key") == nil {
+				p.Keyed = false
+			}
+			for _, field := range r.ChildElements() {
+				p.Fields = appendNew(p.Fields, field.Tag)
+			}
+		}
+		if len(p.Records) == 0 {
+			p.Keyed = false
+		}
+		s.Parts = append(s.Parts, p)
+	}
+	return s, nil
+}
+
+// StringIn reports whether list contains s.
+func StringIn(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidationError is structural problem of language file.
+type ValidationError struct {
+	Path    string // like "/Language/Keys/Record[Key='Tab']"
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return e.Path + ": " + e.Message
+}
+
+// recordPath returns path of record with key, or with position if key is
+// blank.
+func recordPath(partPath string, r *etree.Element, key string, n int) string {
+	if key != "" {
+		return fmt.Sprintf("%s/%s[Key='%s']", partPath, r.Tag, key)
+	}
+	return fmt.Sprintf("%s/%s[%d]", partPath, r.Tag, n)
+}
+
+// Validate returns structural problems of language file data, like baked
+// one, against schema: root Language element, header elements before
+// parts, non-empty Code, allowed parts, records and fields, and unique
+// non-empty keys of keyed parts.
+func Validate(data []byte, s *Schema) []ValidationError {
+	var errs []ValidationError
+	add := func(path, format string, args ...interface{}) {
+		errs = append(errs, ValidationError{Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+	d := etree.NewDocument()
+	if err := d.ReadFromBytes(data); err != nil {
+		add("/", "failed to parse: %v", err)
+		return errs
+	}
+	root := d.Root()
+	if root == nil || root.Tag != "Language" {
+		add("/", "root element is not Language")
+		return errs
+	}
+	var (
+		seen    = make(map[string]bool)
+		inParts bool
+		header  = -1 // index of last header element
+	)
+	for _, e := range root.ChildElements() {
+		path := "/Language/" + e.Tag
+		if seen[e.Tag] {
+			add(path, "duplicate element")
+			continue
+		}
+		seen[e.Tag] = true
+		if i := indexOf(e.Tag, Header); i >= 0 {
+			switch {
+			case inParts:
+				add(path, "must precede parts")
+			case i < header:
+				add(path, "must precede %s", Header[header])
+			default:
+				header = i
+			}
+			if len(e.ChildElements()) > 0 {
+				add(path, "unexpected child elements")
+			}
+			if e.Tag == "Code" && strings.TrimSpace(e.Text()) == "" {
+				add(path, "blank code")
+			}
+			continue
+		}
+		inParts = true
+		p, ok := s.Part(e.Tag)
+		if !ok {
+			add(path, "unexpected part")
+			continue
+		}
+		errs = append(errs, validatePart(path, p, e)...)
+	}
+	if !seen["Code"] {
+		add("/Language", "no Code element")
+	}
+	return errs
+}
+
+func indexOf(s string, list []string) int {
+	for i, v := range list {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func validatePart(path string, p Part, part *etree.Element) []ValidationError {
+	var (
+		errs []ValidationError
+		keys = make(map[string]bool)
+	)
+	for i, r := range part.ChildElements() {
+		var key string
+		if k := r.SelectElement("Key"); k != nil {
+			key = strings.TrimSpace(k.Text())
+		}
+		recPath := recordPath(path, r, key, i+1)
+		if !StringIn(r.Tag, p.Records) {
+			errs = append(errs, ValidationError{Path: recPath, Message: "unexpected record"})
+			continue
+		}
+		if p.Keyed {
+			switch {
+			case key == "":
+				errs = append(errs, ValidationError{Path: recPath, Message: "blank or missing Key"})
+			case keys[key]:
+				errs = append(errs, ValidationError{Path: recPath, Message: "duplicate Key"})
+			}
+			keys[key] = true
+		}
+		for _, field := range r.ChildElements() {
+			if !StringIn(field.Tag, p.Fields) {
+				errs = append(errs, ValidationError{Path: recPath + "/" + field.Tag, Message: "unexpected field"})
+			}
+		}
+	}
+	return errs
+}
@@ -0,0 +1,94 @@
+package resource
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	schema, err := InferSchema(read(t, "Language", "english.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	reagents, ok := schema.Part("Reagents")
+	if !ok || !reagents.Keyed || len(reagents.Records) != 1 || reagents.Records[0] != "RecordReagent" {
+		t.Fatalf("unexpected part %+v", reagents)
+	}
+	if _, ok = schema.Part("Code"); ok {
+		t.Error("header should not be part")
+	}
+	t.Run("Baked", func(t *testing.T) {
+		if errs := Validate(read(t, "default.xml"), schema); len(errs) != 0 {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+	t.Run("Tips", func(t *testing.T) {
+		data := read(t, "Language", "english_tips.xml")
+		tips, err := InferSchema(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, _ := tips.Part("GameTip"); p.Keyed || p.Records[0] != "String" {
+			t.Errorf("unexpected part %+v", p)
+		}
+		if errs := Validate(data, tips); len(errs) != 0 {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		data := []byte(`<Language>
+  <Name>Russian</Name>
+  <Code> </Code>
+  <Reagents>
+    <RecordReagent><Key>Flour</Key><Value>Мука</Value></RecordReagent>
+    <RecordReagent><Key>Flour</Key><Value>Мука</Value></RecordReagent>
+    <RecordReagent><Value>Молоко</Value><Color>red</Color></RecordReagent>
+    <Record><Key>Egg</Key></Record>
+  </Reagents>
+  <Reagents/>
+  <Weapons/>
+  <Font>font_russian</Font>
+</Language>`)
+		expected := []string{
+			"/Language/Code: blank code",
+			"/Language/Reagents/RecordReagent[Key='Flour']: duplicate Key",
+			"/Language/Reagents/RecordReagent[3]: blank or missing Key",
+			"/Language/Reagents/RecordReagent[3]/Color: unexpected field",
+			"/Language/Reagents/Record[Key='Egg']: unexpected record",
+			"/Language/Reagents: duplicate element",
+			"/Language/Weapons: unexpected part",
+			"/Language/Font: must precede parts",
+		}
+		errs := Validate(data, schema)
+		var got []string
+		for _, e := range errs {
+			got = append(got, e.Error())
+		}
+		if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+			t.Errorf("unexpected errors:\n%s", strings.Join(got, "\n"))
+		}
+		for _, data := range []string{"<Language>", "<Root><Code>RU</Code></Root>", "<Language/>"} {
+			if errs := Validate([]byte(data), schema); len(errs) != 1 {
+				t.Errorf("unexpected errors %v of %s", errs, data)
+			}
+		}
+	})
+	t.Run("Font", func(t *testing.T) {
+		original := read(t, "Language", "english_keys.xml")
+		keys, err := InferSchema(original)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := Bake(Options{
+			Original: original,
+			Code:     "RU",
+			Font:     "font_russian",
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if errs := Validate(out, keys); len(errs) != 0 {
+			t.Errorf("unexpected errors %v", errs)
+		}
+	})
+}
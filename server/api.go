@@ -0,0 +1,248 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/st-10n/martian/resource"
+)
+
+// Language is translation language.
+type Language struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	Locale string `json:"locale"` // directory of catalogs
+}
+
+// Catalogs are po files of languages in locales directory, reloaded
+// when files are changed on disk.
+type Catalogs struct {
+	Dir       string
+	Languages []Language
+	// Interval between checks of files, every access checks if zero.
+	Interval time.Duration
+
+	mu      sync.Mutex
+	checked time.Time
+	state   string
+	entries map[string]resource.Entries // by locale
+}
+
+// fingerprint returns string that changes when any po file is changed.
+func (c *Catalogs) fingerprint() (string, error) {
+	b := new(strings.Builder)
+	err := filepath.Walk(c.Dir, func(name string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(name, ".po") {
+			fmt.Fprintf(b, "%s:%d:%d\n", name, info.Size(), info.ModTime().UnixNano())
+		}
+		return nil
+	})
+	return b.String(), err
+}
+
+func (c *Catalogs) reload() error {
+	now := time.Now()
+	if c.entries != nil && now.Sub(c.checked) < c.Interval {
+		return nil
+	}
+	c.checked = now
+	state, err := c.fingerprint()
+	if err != nil {
+		return err
+	}
+	if c.entries != nil && state == c.state {
+		return nil
+	}
+	entries := make(map[string]resource.Entries, len(c.Languages))
+	for _, l := range c.Languages {
+		dir := filepath.Join(c.Dir, l.Locale)
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		if entries[l.Locale], err = resource.ReadCatalogs(os.DirFS(dir), ""); err != nil {
+			return fmt.Errorf("failed to read %s: %v", l.Locale, err)
+		}
+	}
+	c.entries, c.state = entries, state
+	return nil
+}
+
+// Entries returns entries of language locale, reloading catalogs if needed.
+func (c *Catalogs) Entries(locale string) (resource.Entries, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c.entries[locale], nil
+}
+
+// Language returns language by code or locale.
+func (c *Catalogs) Language(name string) (Language, bool) {
+	for _, l := range c.Languages {
+		if strings.EqualFold(l.Code, name) || strings.EqualFold(l.Locale, name) {
+			return l, true
+		}
+	}
+	return Language{}, false
+}
+
+// API is read-only HTTP API of catalogs and baked files:
+//
+//	GET /api/languages                      languages with progress
+//	GET /api/languages/{code}               progress by file
+//	GET /api/languages/{code}/entries?file= entries of language
+//	GET /api/search?q=&lang=&limit=         search by key, context or text
+//	GET /api/baked/{path}                   baked xml file
+type API struct {
+	Catalogs *Catalogs
+	// Assets is directory of baked files (StreamingAssets), blank if
+	// not served.
+	Assets string
+}
+
+// Routes registers API handlers on server.
+func (a *API) Routes(s *Server) {
+	s.Handle("/api/languages", http.HandlerFunc(a.handleLanguages))
+	s.Handle("/api/languages/", http.HandlerFunc(a.handleLanguage))
+	s.Handle("/api/search", http.HandlerFunc(a.handleSearch))
+	s.Handle("/api/baked/", http.HandlerFunc(a.handleBaked))
+}
+
+type languageProgress struct {
+	Language
+	Stats resource.Stats            `json:"stats"`
+	Files map[string]resource.Stats `json:"files,omitempty"`
+}
+
+func (a *API) handleLanguages(w http.ResponseWriter, r *http.Request) {
+	list := make([]languageProgress, 0, len(a.Catalogs.Languages))
+	for _, l := range a.Catalogs.Languages {
+		entries, err := a.Catalogs.Entries(l.Locale)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if entries == nil {
+			continue
+		}
+		list = append(list, languageProgress{
+			Language: l,
+			Stats:    entries.Stats(),
+		})
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *API) handleLanguage(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, "/api/languages/"), "/")
+	l, ok := a.Catalogs.Language(parts[0])
+	if !ok || len(parts) > 2 || (len(parts) == 2 && parts[1] != "entries") {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	entries, err := a.Catalogs.Entries(l.Locale)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if len(parts) == 1 {
+		writeJSON(w, http.StatusOK, languageProgress{
+			Language: l,
+			Stats:    entries.Stats(),
+			Files:    entries.StatsByFile(),
+		})
+		return
+	}
+	file := r.URL.Query().Get("file")
+	result := resource.Entries{}
+	for _, e := range entries {
+		if file == "" || e.File == file {
+			result = append(result, e)
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+type searchResult struct {
+	Language string `json:"language"` // code
+	resource.Entry
+}
+
+func (a *API) handleSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.ToLower(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, errors.New("blank query"))
+		return
+	}
+	limit := 100
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("bad limit"))
+			return
+		}
+		limit = n
+	}
+	languages := a.Catalogs.Languages
+	if name := r.URL.Query().Get("lang"); name != "" {
+		l, ok := a.Catalogs.Language(name)
+		if !ok {
+			writeError(w, http.StatusNotFound, fmt.Errorf("unknown language %q", name))
+			return
+		}
+		languages = []Language{l}
+	}
+	results := []searchResult{}
+	for _, l := range languages {
+		entries, err := a.Catalogs.Entries(l.Locale)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		for _, e := range entries {
+			if len(results) >= limit {
+				break
+			}
+			for _, s := range []string{e.Context, e.ID, e.Original, e.Str} {
+				if strings.Contains(strings.ToLower(s), q) {
+					results = append(results, searchResult{Language: l.Code, Entry: e})
+					break
+				}
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+func (a *API) handleBaked(w http.ResponseWriter, r *http.Request) {
+	name := path.Clean(strings.TrimPrefix(r.URL.Path, "/api/baked/"))
+	if a.Assets == "" || !fs.ValidPath(name) || path.Ext(name) != ".xml" {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	data, err := fs.ReadFile(os.DirFS(a.Assets), name)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", path.Base(name)))
+	w.Write(data)
+}
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/st-10n/martian/resource"
+)
+
+func copyFile(t *testing.T, from, to string) {
+	t.Helper()
+	data, err := ioutil.ReadFile(from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Dir(to), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = ioutil.WriteFile(to, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newTestAPI(t *testing.T) (*Server, string) {
+	t.Helper()
+	dir := t.TempDir()
+	testdata := filepath.Join("..", "resource", "_testdata")
+	copyFile(t, filepath.Join(testdata, "merge_result.po"), filepath.Join(dir, "locales", "ru", "Colors.po"))
+	copyFile(t, filepath.Join(testdata, "Keys-RU.po"), filepath.Join(dir, "locales", "ru", "Keys.po"))
+	copyFile(t, filepath.Join(testdata, "out.xml"), filepath.Join(dir, "assets", "Language", "russian.xml"))
+	s := New(Config{}, &fakeRunner{})
+	a := &API{
+		Catalogs: &Catalogs{
+			Dir: filepath.Join(dir, "locales"),
+			Languages: []Language{
+				{Code: "RU", Name: "Russian", Locale: "ru"},
+				{Code: "DE", Name: "German", Locale: "de"},
+			},
+		},
+		Assets: filepath.Join(dir, "assets"),
+	}
+	a.Routes(s)
+	return s, dir
+}
+
+func get(t *testing.T, s *Server, url string, v interface{}) int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+	if w.Code == http.StatusOK && v != nil {
+		if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w.Code
+}
+
+func TestAPI(t *testing.T) {
+	s, dir := newTestAPI(t)
+	defer s.Close()
+	t.Run("Languages", func(t *testing.T) {
+		var list []languageProgress
+		if code := get(t, s, "/api/languages", &list); code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(list) != 1 || list[0].Code != "RU" || list[0].Stats.Total == 0 {
+			t.Errorf("unexpected languages %+v", list)
+		}
+	})
+	t.Run("Language", func(t *testing.T) {
+		var p languageProgress
+		if code := get(t, s, "/api/languages/ru", &p); code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if p.Files["Colors"].Total != 12 || p.Files["Keys"].Total == 0 {
+			t.Errorf("unexpected progress %+v", p)
+		}
+		if code := get(t, s, "/api/languages/xx", nil); code != http.StatusNotFound {
+			t.Errorf("unexpected status %d", code)
+		}
+	})
+	t.Run("Entries", func(t *testing.T) {
+		var entries resource.Entries
+		if code := get(t, s, "/api/languages/RU/entries?file=Colors", &entries); code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(entries) != 12 {
+			t.Errorf("unexpected entries count %d", len(entries))
+		}
+	})
+	t.Run("Search", func(t *testing.T) {
+		var results []searchResult
+		if code := get(t, s, "/api/search?q=khaki", &results); code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(results) != 1 || results[0].Str != "Хаки" || results[0].Language != "RU" {
+			t.Errorf("unexpected results %+v", results)
+		}
+		if code := get(t, s, "/api/search?q=keys.&lang=ru&limit=5", &results); code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(results) != 5 {
+			t.Errorf("unexpected results count %d", len(results))
+		}
+		if code := get(t, s, "/api/search", nil); code != http.StatusBadRequest {
+			t.Errorf("unexpected status %d", code)
+		}
+	})
+	t.Run("Baked", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/baked/Language/russian.xml", nil))
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "<Language") {
+			t.Errorf("unexpected response %d", w.Code)
+		}
+		for _, name := range []string{"Language/german.xml", "../locales/ru/Keys.po", "Language"} {
+			if code := get(t, s, "/api/baked/"+name, nil); code == http.StatusOK {
+				t.Errorf("%s: unexpected status %d", name, code)
+			}
+		}
+	})
+	t.Run("Reload", func(t *testing.T) {
+		name := filepath.Join(dir, "locales", "ru", "Colors.po")
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data = []byte(strings.Replace(string(data), "Хаки", "Цвет хаки", 1))
+		if err = ioutil.WriteFile(name, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+		var results []searchResult
+		if code := get(t, s, "/api/search?q=цвет+хаки", &results); code != http.StatusOK {
+			t.Fatalf("unexpected status %d", code)
+		}
+		if len(results) != 1 {
+			t.Errorf("catalogs are not reloaded: %+v", results)
+		}
+	})
+}
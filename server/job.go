@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+)
+
+// Job states.
+const (
+	Queued    = "queued"
+	Running   = "running"
+	Succeeded = "succeeded"
+	Failed    = "failed"
+)
+
+// JobStatus is JSON representation of job.
+type JobStatus struct {
+	ID         int        `json:"id"`
+	Repository string     `json:"repository"`
+	Ref        string     `json:"ref,omitempty"`
+	Commit     string     `json:"commit,omitempty"`
+	State      string     `json:"state"`
+	Error      string     `json:"error,omitempty"`
+	Created    time.Time  `json:"created"`
+	Started    *time.Time `json:"started,omitempty"`
+	Finished   *time.Time `json:"finished,omitempty"`
+	Log        string     `json:"log,omitempty"`
+}
+
+// Job is pipeline run for repository push.
+type Job struct {
+	ID         int
+	Repository string
+
+	mu     sync.Mutex
+	status JobStatus
+	buf    bytes.Buffer
+	done   chan struct{}
+}
+
+func newJob(id int, repository, ref, commit string) *Job {
+	return &Job{
+		ID:         id,
+		Repository: repository,
+		status: JobStatus{
+			ID:         id,
+			Repository: repository,
+			Ref:        ref,
+			Commit:     commit,
+			State:      Queued,
+			Created:    time.Now(),
+		},
+		done: make(chan struct{}),
+	}
+}
+
+func (j *Job) start() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.State = Running
+	j.status.Started = &now
+}
+
+func (j *Job) finish(err error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	j.status.Finished = &now
+	j.status.State = Succeeded
+	if err != nil {
+		j.status.State = Failed
+		j.status.Error = err.Error()
+	}
+	close(j.done)
+}
+
+// Status returns current status of job without log.
+func (j *Job) Status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.status
+}
+
+// Done reports whether job is finished.
+func (j *Job) Done() bool {
+	select {
+	case <-j.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Wait blocks until job is finished or ctx is done.
+func (j *Job) Wait(ctx context.Context) error {
+	select {
+	case <-j.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Log returns output of job.
+func (j *Job) Log() string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.buf.String()
+}
+
+type jobLog struct {
+	j *Job
+}
+
+func (l jobLog) Write(p []byte) (int, error) {
+	l.j.mu.Lock()
+	defer l.j.mu.Unlock()
+	return l.j.buf.Write(p)
+}
+
+func (j *Job) log() io.Writer {
+	return jobLog{j: j}
+}
+
+// Runner runs martian command with arguments, writing output to w.
+type Runner interface {
+	Run(ctx context.Context, args []string, w io.Writer) error
+}
+
+// ExecRunner runs commands with martian executable in directory.
+type ExecRunner struct {
+	Executable string // os.Executable if blank
+	Dir        string
+	Args       []string // prepended to every command, like ["--config", "martian.yml"]
+}
+
+// Run executes command.
+func (r ExecRunner) Run(ctx context.Context, args []string, w io.Writer) error {
+	exe := r.Executable
+	if exe == "" {
+		var err error
+		if exe, err = os.Executable(); err != nil {
+			return err
+		}
+	}
+	cmd := exec.CommandContext(ctx, exe, append(append([]string{}, r.Args...), args...)...)
+	cmd.Dir = r.Dir
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
+
+// pull pulls changes of current branch from origin.
+func pull(dir string) error {
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		return err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return err
+	}
+	err = wt.Pull(&git.PullOptions{
+		RemoteName: "origin",
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,308 @@
+// Package server implements martian HTTP server that runs localization
+// pipeline on repository push webhooks.
+package server
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Repository is local working copy that is updated by webhooks.
+type Repository struct {
+	Dir    string `mapstructure:"dir"`
+	Branch string `mapstructure:"branch"` // all branches if blank
+	Pull   bool   `mapstructure:"pull"`   // pull before running pipeline
+}
+
+// Config of server.
+type Config struct {
+	// Secret of webhooks, signatures are not checked if blank.
+	Secret string `mapstructure:"secret"`
+	// Repositories by name, like "resources".
+	Repositories map[string]Repository `mapstructure:"repositories"`
+	// Pipeline is list of martian commands with arguments, like
+	// ["gen", "-i", "resources", "-o", "locales"].
+	Pipeline [][]string `mapstructure:"pipeline"`
+	// MaxJobs limits count of finished jobs that are kept, default is 100.
+	MaxJobs int `mapstructure:"max_jobs"`
+	// Dir is working directory of pipeline, relative directories of
+	// repositories are resolved against it. Current directory if blank.
+	Dir string `mapstructure:"-"`
+}
+
+// Server handles webhooks and exposes jobs over JSON API:
+//
+//	POST /hooks/push      GitHub or Gitea push webhook
+//	GET  /api/jobs        list of jobs without logs
+//	GET  /api/jobs/{id}   job with log
+type Server struct {
+	cfg    Config
+	runner Runner
+	mux    *http.ServeMux
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	jobs   []*Job
+	nextID int
+	queue  chan *Job
+}
+
+// New creates server that runs pipeline steps with runner.
+func New(c Config, r Runner) *Server {
+	if c.MaxJobs == 0 {
+		c.MaxJobs = 100
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	s := &Server{
+		cfg:    c,
+		runner: r,
+		mux:    http.NewServeMux(),
+		ctx:    ctx,
+		cancel: cancel,
+		queue:  make(chan *Job, 100),
+	}
+	s.wg.Add(1)
+	go s.work(s.queue)
+	s.mux.HandleFunc("/hooks/push", s.handlePush)
+	s.mux.HandleFunc("/api/jobs", s.handleJobs)
+	s.mux.HandleFunc("/api/jobs/", s.handleJob)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Handle registers additional handler for pattern.
+func (s *Server) Handle(pattern string, h http.Handler) {
+	s.mux.Handle(pattern, h)
+}
+
+// Close cancels running jobs and waits for workers.
+func (s *Server) Close() error {
+	s.cancel()
+	s.mu.Lock()
+	close(s.queue)
+	s.mu.Unlock()
+	s.wg.Wait()
+	return nil
+}
+
+// Enqueue adds job for repository to the queue. Pipeline updates working
+// copies of all repositories, so only one pipeline runs at time.
+func (s *Server) Enqueue(repository, ref, commit string) (*Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx.Err() != nil {
+		return nil, errors.New("server is closed")
+	}
+	s.nextID++
+	j := newJob(s.nextID, repository, ref, commit)
+	s.jobs = append(s.jobs, j)
+	s.trim()
+	select {
+	case s.queue <- j:
+	default:
+		j.finish(errors.New("queue is full"))
+	}
+	return j, nil
+}
+
+// trim removes oldest finished jobs over the limit.
+func (s *Server) trim() {
+	for len(s.jobs) > s.cfg.MaxJobs {
+		i := -1
+		for k, j := range s.jobs {
+			if j.Done() {
+				i = k
+				break
+			}
+		}
+		if i < 0 {
+			return
+		}
+		s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+	}
+}
+
+func (s *Server) work(q <-chan *Job) {
+	defer s.wg.Done()
+	for j := range q {
+		if s.ctx.Err() != nil {
+			j.finish(s.ctx.Err())
+			continue
+		}
+		j.start()
+		j.finish(s.run(j))
+	}
+}
+
+func (s *Server) run(j *Job) error {
+	repo := s.cfg.Repositories[j.Repository]
+	if repo.Pull {
+		dir := repo.Dir
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(s.cfg.Dir, dir)
+		}
+		fmt.Fprintf(j.log(), "$ git pull (%s)\n", dir)
+		if err := pull(dir); err != nil {
+			return fmt.Errorf("failed to pull %s: %v", dir, err)
+		}
+	}
+	for _, step := range s.cfg.Pipeline {
+		if err := s.ctx.Err(); err != nil {
+			return err
+		}
+		fmt.Fprintf(j.log(), "$ martian %s\n", strings.Join(step, " "))
+		if err := s.runner.Run(s.ctx, step, j.log()); err != nil {
+			return fmt.Errorf("%s: %v", step[0], err)
+		}
+	}
+	return nil
+}
+
+// Job returns job by id or nil.
+func (s *Server) Job(id int) *Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, j := range s.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, struct {
+		Error string `json:"error"`
+	}{
+		Error: err.Error(),
+	})
+}
+
+// pushEvent is common part of GitHub and Gitea push payloads.
+type pushEvent struct {
+	Ref        string `json:"ref"`
+	After      string `json:"after"`
+	Repository struct {
+		Name     string `json:"name"`
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// checkSignature checks GitHub (X-Hub-Signature-256) or Gitea
+// (X-Gitea-Signature) HMAC-SHA256 signature of body.
+func checkSignature(secret string, h http.Header, body []byte) bool {
+	sig := strings.TrimPrefix(h.Get("X-Hub-Signature-256"), "sha256=")
+	if sig == "" {
+		sig = h.Get("X-Gitea-Signature")
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil || len(got) == 0 {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+func (s *Server) handlePush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, 25<<20))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if s.cfg.Secret != "" && !checkSignature(s.cfg.Secret, r.Header, body) {
+		writeError(w, http.StatusUnauthorized, errors.New("bad signature"))
+		return
+	}
+	event := r.Header.Get("X-GitHub-Event")
+	if event == "" {
+		event = r.Header.Get("X-Gitea-Event")
+	}
+	switch event {
+	case "ping":
+		w.WriteHeader(http.StatusNoContent)
+		return
+	case "push":
+	default:
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported event %q", event))
+		return
+	}
+	var p pushEvent
+	if err = json.Unmarshal(body, &p); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("bad payload: %v", err))
+		return
+	}
+	name := strings.ToLower(p.Repository.Name)
+	repo, ok := s.cfg.Repositories[name]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown repository %q", p.Repository.FullName))
+		return
+	}
+	if repo.Branch != "" && p.Ref != "refs/heads/"+repo.Branch {
+		// Push to other branch.
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	j, err := s.Enqueue(name, p.Ref, p.After)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, j.Status())
+}
+
+func (s *Server) handleJobs(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	list := make([]JobStatus, 0, len(s.jobs))
+	for i := len(s.jobs) - 1; i >= 0; i-- {
+		// Newest first.
+		list = append(list, s.jobs[i].Status())
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleJob(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/api/jobs/"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, errors.New("not found"))
+		return
+	}
+	j := s.Job(id)
+	if j == nil {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	st := j.Status()
+	st.Log = j.Log()
+	writeJSON(w, http.StatusOK, st)
+}
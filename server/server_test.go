@@ -0,0 +1,245 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRunner records commands and fails on "fail" command.
+type fakeRunner struct {
+	mu      sync.Mutex
+	running int
+	max     int
+	calls   [][]string
+}
+
+func (r *fakeRunner) Run(ctx context.Context, args []string, w io.Writer) error {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.max {
+		r.max = r.running
+	}
+	r.calls = append(r.calls, args)
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.running--
+		r.mu.Unlock()
+	}()
+	time.Sleep(time.Millisecond)
+	fmt.Fprintf(w, "ran %s\n", args[0])
+	if args[0] == "fail" {
+		return errors.New("exit status 1")
+	}
+	return nil
+}
+
+const testSecret = "secret"
+
+func sign(body []byte) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func push(t *testing.T, s *Server, repo, ref string, header func(h http.Header, body []byte)) *httptest.ResponseRecorder {
+	t.Helper()
+	body := []byte(fmt.Sprintf(`{"ref": %q, "after": "abc", "repository": {"name": %q, "full_name": "st-l10n/%s"}}`, ref, repo, repo))
+	req := httptest.NewRequest(http.MethodPost, "/hooks/push", bytes.NewReader(body))
+	header(req.Header, body)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func github(h http.Header, body []byte) {
+	h.Set("X-GitHub-Event", "push")
+	h.Set("X-Hub-Signature-256", "sha256="+sign(body))
+}
+
+func gitea(h http.Header, body []byte) {
+	h.Set("X-Gitea-Event", "push")
+	h.Set("X-Gitea-Signature", sign(body))
+}
+
+func newTestServer(pipeline ...[]string) (*Server, *fakeRunner) {
+	r := &fakeRunner{}
+	return New(Config{
+		Secret: testSecret,
+		Repositories: map[string]Repository{
+			"resources": {Branch: "master"},
+			"locales":   {},
+		},
+		Pipeline: pipeline,
+	}, r), r
+}
+
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) JobStatus {
+	t.Helper()
+	var st JobStatus
+	if err := json.NewDecoder(w.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	return st
+}
+
+func waitJob(t *testing.T, s *Server, id int) JobStatus {
+	t.Helper()
+	j := s.Job(id)
+	if j == nil {
+		t.Fatalf("no job %d", id)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := j.Wait(ctx); err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/api/jobs/%d", id), nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", w.Code)
+	}
+	return decodeJob(t, w)
+}
+
+func TestPush(t *testing.T) {
+	s, r := newTestServer([]string{"gen"}, []string{"bake"})
+	defer s.Close()
+	for _, sign := range []func(http.Header, []byte){github, gitea} {
+		w := push(t, s, "resources", "refs/heads/master", sign)
+		if w.Code != http.StatusAccepted {
+			t.Fatalf("unexpected status %d: %s", w.Code, w.Body)
+		}
+		st := waitJob(t, s, decodeJob(t, w).ID)
+		if st.State != Succeeded || st.Commit != "abc" || st.Repository != "resources" {
+			t.Errorf("unexpected job %+v", st)
+		}
+		if st.Log != "$ martian gen\nran gen\n$ martian bake\nran bake\n" {
+			t.Errorf("unexpected log %q", st.Log)
+		}
+	}
+	if len(r.calls) != 4 {
+		t.Errorf("unexpected calls %v", r.calls)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/jobs", nil))
+	var list []JobStatus
+	if err := json.NewDecoder(w.Body).Decode(&list); err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].ID != 2 || list[0].Log != "" {
+		t.Errorf("unexpected jobs %+v", list)
+	}
+}
+
+func TestPushRejected(t *testing.T) {
+	s, r := newTestServer([]string{"gen"})
+	defer s.Close()
+	for _, tt := range []struct {
+		Name   string
+		Repo   string
+		Ref    string
+		Header func(http.Header, []byte)
+		Code   int
+	}{
+		{Name: "Signature", Repo: "resources", Ref: "refs/heads/master", Code: http.StatusUnauthorized,
+			Header: func(h http.Header, body []byte) {
+				h.Set("X-GitHub-Event", "push")
+				h.Set("X-Hub-Signature-256", "sha256="+sign([]byte("other")))
+			},
+		},
+		{Name: "Unknown", Repo: "other", Ref: "refs/heads/master", Header: github, Code: http.StatusNotFound},
+		{Name: "Branch", Repo: "resources", Ref: "refs/heads/dev", Header: github, Code: http.StatusNoContent},
+		{Name: "Event", Repo: "resources", Ref: "refs/heads/master", Code: http.StatusBadRequest,
+			Header: func(h http.Header, body []byte) {
+				h.Set("X-GitHub-Event", "issues")
+				h.Set("X-Hub-Signature-256", "sha256="+sign(body))
+			},
+		},
+	} {
+		t.Run(tt.Name, func(t *testing.T) {
+			if w := push(t, s, tt.Repo, tt.Ref, tt.Header); w.Code != tt.Code {
+				t.Errorf("unexpected status %d, expected %d", w.Code, tt.Code)
+			}
+		})
+	}
+	if len(r.calls) != 0 {
+		t.Errorf("unexpected calls %v", r.calls)
+	}
+}
+
+func TestPipelineFailure(t *testing.T) {
+	s, r := newTestServer([]string{"fail"}, []string{"bake"})
+	defer s.Close()
+	w := push(t, s, "locales", "refs/heads/master", github)
+	st := waitJob(t, s, decodeJob(t, w).ID)
+	if st.State != Failed || !strings.Contains(st.Error, "fail: exit status 1") {
+		t.Errorf("unexpected job %+v", st)
+	}
+	if len(r.calls) != 1 {
+		t.Errorf("next steps should not run: %v", r.calls)
+	}
+}
+
+func TestOnePipeline(t *testing.T) {
+	s, r := newTestServer([]string{"gen"}, []string{"bake"}, []string{"package"})
+	defer s.Close()
+	var jobs []*Job
+	for i := 0; i < 5; i++ {
+		// Pipeline touches all repositories.
+		repo := "resources"
+		if i%2 == 1 {
+			repo = "locales"
+		}
+		j, err := s.Enqueue(repo, "refs/heads/master", "abc")
+		if err != nil {
+			t.Fatal(err)
+		}
+		jobs = append(jobs, j)
+	}
+	for _, j := range jobs {
+		if err := j.Wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if r.max != 1 {
+		t.Errorf("%d pipelines run concurrently", r.max)
+	}
+	if len(r.calls) != 15 {
+		t.Errorf("unexpected calls count %d", len(r.calls))
+	}
+}
+
+func TestPullDir(t *testing.T) {
+	dir := t.TempDir()
+	s := New(Config{
+		Repositories: map[string]Repository{
+			"locales": {Dir: "locales", Pull: true},
+		},
+		Pipeline: [][]string{{"gen"}},
+		Dir:      dir,
+	}, &fakeRunner{})
+	defer s.Close()
+	j, err := s.Enqueue("locales", "refs/heads/master", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := waitJob(t, s, j.ID)
+	if st.State != Failed || !strings.Contains(st.Error, "failed to pull "+filepath.Join(dir, "locales")) {
+		t.Errorf("unexpected job %+v", st)
+	}
+}
@@ -0,0 +1,141 @@
+// Package vcs implements automated commits of generated files to git
+// repositories.
+package vcs
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+	"gopkg.in/src-d/go-git.v4/plumbing/object"
+	"gopkg.in/src-d/go-git.v4/plumbing/transport"
+)
+
+// Prefix of automated commit messages, the commits with such prefix are
+// not treated as translation updates.
+const Prefix = "automated"
+
+// match reports whether slash-separated name matches any of patterns,
+// see path.Match.
+func match(name string, patterns []string) (bool, error) {
+	for _, p := range patterns {
+		ok, err := path.Match(p, name)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Stage adds changed files of worktree that match patterns to index,
+// removing deleted ones, and returns sorted names of staged files.
+//
+// Patterns are slash-separated and relative to worktree, like "*/*.po".
+// Returns error if index already has changes, so changes that are staged
+// by other means are not committed as automated ones.
+func Stage(repo *git.Repository, patterns []string) ([]string, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return nil, err
+	}
+	var foreign []string
+	for name, s := range status {
+		if isStaged(s) {
+			foreign = append(foreign, name)
+		}
+	}
+	if len(foreign) > 0 {
+		sort.Strings(foreign)
+		return nil, fmt.Errorf("index already has changes: %s", strings.Join(foreign, ", "))
+	}
+	var staged []string
+	for name, s := range status {
+		if s.Worktree == git.Unmodified {
+			continue
+		}
+		ok, err := match(name, patterns)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if s.Worktree == git.Deleted {
+			_, err = wt.Remove(name)
+		} else {
+			_, err = wt.Add(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		staged = append(staged, name)
+	}
+	sort.Strings(staged)
+	return staged, nil
+}
+
+// HasStaged reports whether index has changes to commit.
+func HasStaged(repo *git.Repository) (bool, error) {
+	wt, err := repo.Worktree()
+	if err != nil {
+		return false, err
+	}
+	status, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+	for _, s := range status {
+		if isStaged(s) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func isStaged(s *git.FileStatus) bool {
+	return s.Staging != git.Unmodified && s.Staging != git.Untracked
+}
+
+// Commit commits staged changes with provided message and author.
+//
+// Returns zero hash if there is nothing to commit.
+func Commit(repo *git.Repository, message, name, email string) (plumbing.Hash, error) {
+	ok, err := HasStaged(repo)
+	if err != nil || !ok {
+		return plumbing.ZeroHash, err
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return wt.Commit(message, &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  name,
+			Email: email,
+			When:  time.Now(),
+		},
+	})
+}
+
+// Push pushes all branches to remote. Up-to-date remote is not an error.
+func Push(repo *git.Repository, remote string, auth transport.AuthMethod) error {
+	err := repo.Push(&git.PushOptions{
+		RemoteName: remote,
+		Auth:       auth,
+	})
+	if err == git.NoErrAlreadyUpToDate {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package vcs
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/src-d/go-git.v4"
+	"gopkg.in/src-d/go-git.v4/config"
+	"gopkg.in/src-d/go-git.v4/plumbing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, data := range files {
+		name = filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestCommit(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := []string{"*/*.po", "*/*.pot"}
+	writeFiles(t, dir, map[string]string{
+		"ru/Keys.po":  "ru",
+		"ru/Keys.pot": "template",
+		"de/Keys.po":  "de",
+		"notes.txt":   "not generated",
+	})
+	staged, err := Stage(repo, patterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"de/Keys.po", "ru/Keys.po", "ru/Keys.pot"}; !reflect.DeepEqual(staged, expected) {
+		t.Errorf("staged %v, expected %v", staged, expected)
+	}
+	hash, err := Commit(repo, Prefix+": update", "Martian", "martian@localhost")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash.IsZero() {
+		t.Fatal("should commit")
+	}
+	c, err := repo.CommitObject(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.File("notes.txt"); err == nil {
+		t.Error("notes.txt should not be committed")
+	}
+	if c.Message != "automated: update" || c.Author.Name != "Martian" {
+		t.Errorf("unexpected commit %s", c)
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		if staged, err = Stage(repo, patterns); err != nil {
+			t.Fatal(err)
+		}
+		if len(staged) != 0 {
+			t.Errorf("unexpected staged %v", staged)
+		}
+		hash, err := Commit(repo, "automated: nothing", "Martian", "martian@localhost")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !hash.IsZero() {
+			t.Error("should skip empty commit")
+		}
+	})
+	t.Run("Deleted", func(t *testing.T) {
+		if err = os.Remove(filepath.Join(dir, "de", "Keys.po")); err != nil {
+			t.Fatal(err)
+		}
+		writeFiles(t, dir, map[string]string{"ru/Keys.po": "ru updated"})
+		if staged, err = Stage(repo, patterns); err != nil {
+			t.Fatal(err)
+		}
+		if expected := []string{"de/Keys.po", "ru/Keys.po"}; !reflect.DeepEqual(staged, expected) {
+			t.Errorf("staged %v, expected %v", staged, expected)
+		}
+		if hash, err = Commit(repo, "automated: update", "Martian", "martian@localhost"); err != nil {
+			t.Fatal(err)
+		}
+		c, err := repo.CommitObject(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = c.File("de/Keys.po"); err == nil {
+			t.Error("de/Keys.po should be removed")
+		}
+	})
+	t.Run("Foreign", func(t *testing.T) {
+		writeFiles(t, dir, map[string]string{
+			"notes.txt":  "staged by hand",
+			"ru/Keys.po": "ru foreign",
+		})
+		wt, err := repo.Worktree()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = wt.Add("notes.txt"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = Stage(repo, patterns); err == nil {
+			t.Fatal("should fail with foreign staged changes")
+		}
+		if _, err = wt.Remove("notes.txt"); err != nil {
+			t.Fatal(err)
+		}
+		writeFiles(t, dir, map[string]string{"ru/Keys.po": "ru updated"})
+	})
+	t.Run("Push", func(t *testing.T) {
+		remoteDir := t.TempDir()
+		remote, err := git.PlainInit(remoteDir, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = repo.CreateRemote(&config.RemoteConfig{
+			Name: "origin",
+			URLs: []string{remoteDir},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if err = Push(repo, "origin", nil); err != nil {
+			t.Fatal(err)
+		}
+		ref, err := remote.Reference(plumbing.Master, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.Hash() != hash {
+			t.Errorf("remote is %s, expected %s", ref.Hash(), hash)
+		}
+		if err = Push(repo, "origin", nil); err != nil {
+			t.Errorf("up-to-date push should not fail: %v", err)
+		}
+	})
+}